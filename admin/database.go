@@ -0,0 +1,142 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"firebase.google.com/go/v4/db"
+)
+
+// ==================================================================
+// https://firebase.google.com/docs/database/admin/save-data
+// ==================================================================
+
+func deleteData(ctx context.Context, client *db.Client) {
+	// [START rtdb_delete]
+	ref := client.NewRef("server/saving-data/fireblog/posts")
+
+	// Delete removes the data at the location.
+	if err := ref.Child("post-1").Delete(ctx); err != nil {
+		log.Fatalf("error deleting data: %v\n", err)
+	}
+
+	// Setting a location to nil is equivalent to deleting it.
+	if err := ref.Child("post-2").Set(ctx, nil); err != nil {
+		log.Fatalf("error deleting data: %v\n", err)
+	}
+	// The database does not store empty objects or arrays. A parent whose
+	// last child was deleted is removed automatically as well.
+	// [END rtdb_delete]
+}
+
+func setOnDisconnect(ctx context.Context, client *db.Client) {
+	// [START rtdb_on_disconnect]
+	// onDisconnect handlers are registered on a client's realtime connection
+	// and run by the server when that connection drops. The Go Admin SDK
+	// talks to the database over REST and never holds such a connection, so
+	// it can't register them, and the REST API has no equivalent. Have each
+	// client register its own handler with its SDK, e.g. on the web:
+	//
+	//   onDisconnect(ref(db, `status/${uid}`)).update({online: false});
+	//
+	// As a server-side fallback, have clients also write a lastSeen
+	// timestamp (in milliseconds) while they are online, and periodically
+	// clear the presence flag of clients that have stopped reporting.
+	cutoff := timeToMillis(time.Now().Add(-5 * time.Minute))
+	stale, err := client.NewRef("status").
+		OrderByChild("lastSeen").
+		EndAt(cutoff).
+		GetOrdered(ctx)
+	if err != nil {
+		log.Fatalf("error querying presence: %v\n", err)
+	}
+
+	// Clear every stale flag in a single atomic multi-path update. Querying
+	// on lastSeen needs an ".indexOn": "lastSeen" rule on /status.
+	updates := map[string]interface{}{}
+	for _, node := range stale {
+		updates[node.Key()+"/online"] = false
+	}
+	if len(updates) > 0 {
+		if err := client.NewRef("status").Update(ctx, updates); err != nil {
+			log.Fatalf("error clearing presence: %v\n", err)
+		}
+	}
+	log.Printf("marked %d clients offline\n", len(updates))
+	// [END rtdb_on_disconnect]
+}
+
+// ==================================================================
+// https://firebase.google.com/docs/database/admin/retrieve-data
+// ==================================================================
+
+func readIntoStruct(ctx context.Context, client *db.Client) {
+	// [START rtdb_read_struct]
+	// The Realtime Database client marshals data with encoding/json, so field
+	// names come from `json` tags. Firestore uses `firestore` tags instead, and
+	// ignores `json` tags entirely.
+	type User struct {
+		DateOfBirth string `json:"date_of_birth,omitempty"`
+		FullName    string `json:"full_name,omitempty"`
+		Nickname    string `json:"nickname,omitempty"`
+	}
+
+	var user User
+	if err := client.NewRef("server/saving-data/fireblog/users/alanisawesome").Get(ctx, &user); err != nil {
+		log.Fatalf("error reading user: %v\n", err)
+	}
+	// Fields missing from the stored data keep their zero values, and stored
+	// fields without a matching struct field are ignored.
+	log.Printf("%+v\n", user)
+	// [END rtdb_read_struct]
+}
+
+func paginateRTDB(ctx context.Context, client *db.Client) {
+	// [START rtdb_paginate]
+	// The Realtime Database has no cursor objects. Instead, carry the key to
+	// start from into the next query. Each query asks for one result more
+	// than the page size: if it comes back, there is another page, and its
+	// key is where that page starts. StartAt is inclusive, so nothing is
+	// read twice.
+	const pageSize = 20
+	ref := client.NewRef("dinosaurs")
+	nextKey := ""
+	for {
+		q := ref.OrderByKey().LimitToFirst(pageSize + 1)
+		if nextKey != "" {
+			q = q.StartAt(nextKey)
+		}
+		page, err := q.GetOrdered(ctx)
+		if err != nil {
+			log.Fatalf("error querying dinosaurs: %v\n", err)
+		}
+
+		hasNext := len(page) > pageSize
+		if hasNext {
+			nextKey = page[pageSize].Key()
+			page = page[:pageSize]
+		}
+		for _, node := range page {
+			log.Printf("read dinosaur: %s\n", node.Key())
+		}
+		if !hasNext {
+			break
+		}
+	}
+	// [END rtdb_paginate]
+}
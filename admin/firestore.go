@@ -0,0 +1,960 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ==================================================================
+// https://firebase.google.com/docs/firestore/data-model
+// ==================================================================
+
+func readSubcollection(ctx context.Context, client *firestore.Client) {
+	// [START fs_subcollection]
+	// A subcollection exists independently of its parent document. It can be
+	// read even if cities/SF has no fields, and deleting cities/SF does not
+	// delete the documents in its subcollections.
+	iter := client.Collection("cities").Doc("SF").Collection("neighborhoods").Documents(ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Fatalf("error reading neighborhoods: %v\n", err)
+		}
+		log.Printf("%s => %v\n", doc.Ref.ID, doc.Data())
+	}
+	// [END fs_subcollection]
+}
+
+func listDocumentCollections(ctx context.Context, client *firestore.Client) {
+	// [START fs_list_subcollections]
+	doc := client.Collection("cities").Doc("SF")
+	iter := doc.Collections(ctx)
+	for {
+		col, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Fatalf("error listing subcollections: %v\n", err)
+		}
+		log.Printf("Found subcollection with id: %s\n", col.ID)
+	}
+	// [END fs_list_subcollections]
+}
+
+func navigateParents(ctx context.Context, client *firestore.Client) {
+	// [START fs_navigate_parents]
+	doc := client.Collection("cities").Doc("SF").
+		Collection("neighborhoods").Doc("mission").
+		Collection("restaurants").Doc("taqueria")
+
+	// Walk up the hierarchy, alternating between collections and documents.
+	// A root collection has a nil parent document. The same works for the
+	// Ref of a snapshot returned by a collection group query.
+	var breadcrumbs []string
+	for d := doc; d != nil; d = d.Parent.Parent {
+		breadcrumbs = append([]string{d.Parent.ID, d.ID}, breadcrumbs...)
+	}
+	log.Printf("Path: %s\n", strings.Join(breadcrumbs, " > "))
+	// [END fs_navigate_parents]
+}
+
+// ==================================================================
+// https://firebase.google.com/docs/firestore/query-data/queries
+// ==================================================================
+
+func orQuery(ctx context.Context, client *firestore.Client) {
+	// [START fs_or_query]
+	// state == "CA" OR population > 1000000
+	caOrLarge := firestore.OrFilter{
+		Filters: []firestore.EntityFilter{
+			firestore.PropertyFilter{Path: "state", Operator: "==", Value: "CA"},
+			firestore.PropertyFilter{Path: "population", Operator: ">", Value: 1000000},
+		},
+	}
+
+	// Filters can be nested: capital == true AND (state == "CA" OR population > 1000000)
+	filter := firestore.AndFilter{
+		Filters: []firestore.EntityFilter{
+			firestore.PropertyFilter{Path: "capital", Operator: "==", Value: true},
+			caOrLarge,
+		},
+	}
+
+	// Firestore converts the filter to disjunctive normal form, which may have
+	// at most 30 disjunctions. Each disjunction is served by its own index, so
+	// combinations of fields may require composite indexes.
+	iter := client.Collection("cities").WhereEntity(filter).Documents(ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Fatalf("error querying cities: %v\n", err)
+		}
+		log.Printf("%s => %v\n", doc.Ref.ID, doc.Data())
+	}
+	// [END fs_or_query]
+}
+
+func selectFields(ctx context.Context, client *firestore.Client) {
+	// [START fs_select_fields]
+	// Only the selected fields are returned, which reduces bandwidth. Fields
+	// that were not selected are absent from Data(). Calling Select() with no
+	// arguments returns only the document references, with no fields at all.
+	iter := client.Collection("cities").Select("name", "population").Documents(ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Fatalf("error querying cities: %v\n", err)
+		}
+		log.Printf("%s => %v\n", doc.Ref.ID, doc.Data())
+	}
+	// [END fs_select_fields]
+}
+
+func queryWithFieldPath(ctx context.Context, client *firestore.Client) {
+	// [START fs_fieldpath_query]
+	// Where splits the path on dots, so "metrics.a.b.c" refers to the nested
+	// field metrics -> a -> b -> c. To match a map key that itself contains
+	// dots (or other special characters), pass each path component separately.
+	q := client.Collection("stats").WherePath(firestore.FieldPath{"metrics", "a.b.c"}, "==", 1)
+	iter := q.Documents(ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Fatalf("error querying stats: %v\n", err)
+		}
+		log.Printf("%s => %v\n", doc.Ref.ID, doc.Data())
+	}
+	// [END fs_fieldpath_query]
+}
+
+func queryNullAndNaN(ctx context.Context, client *firestore.Client) {
+	// [START fs_query_null_nan]
+	cities := client.Collection("cities")
+
+	// Matches documents whose capital field is null. Documents without a
+	// capital field are not matched.
+	nullQuery := cities.Where("capital", "==", nil)
+
+	// NaN is not equal to anything, including itself, but Firestore turns
+	// an equality filter on NaN into an "is NaN" check, so this matches
+	// documents whose population field is NaN.
+	nanQuery := cities.Where("population", "==", math.NaN())
+
+	// nil and NaN can only be compared with == and !=. A != nil filter
+	// matches documents where the field exists and is not null, while range
+	// filters such as Where("capital", ">", nil) are rejected.
+	for _, q := range []firestore.Query{nullQuery, nanQuery} {
+		docs, err := q.Documents(ctx).GetAll()
+		if err != nil {
+			log.Fatalf("error querying cities: %v\n", err)
+		}
+		for _, doc := range docs {
+			log.Printf("%s => %v\n", doc.Ref.ID, doc.Data())
+		}
+	}
+	// [END fs_query_null_nan]
+}
+
+func queryGetAll(ctx context.Context, client *firestore.Client) {
+	// [START fs_query_getall]
+	// GetAll drains the iterator and returns every matching document at
+	// once. All results are held in memory, so bound the query with Limit;
+	// for large or unbounded result sets, loop over Next instead and handle
+	// one document at a time.
+	docs, err := client.Collection("cities").
+		Where("capital", "==", true).
+		Limit(50).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		log.Fatalf("error getting cities: %v\n", err)
+	}
+	for _, doc := range docs {
+		log.Printf("%s => %v\n", doc.Ref.ID, doc.Data())
+	}
+	// [END fs_query_getall]
+}
+
+func multiFieldCursor(ctx context.Context, client *firestore.Client) {
+	// [START fs_multifield_cursor]
+	cities := client.Collection("cities")
+	query := cities.OrderBy("state", firestore.Asc).
+		OrderBy("population", firestore.Asc).
+		Limit(25)
+
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		log.Fatalf("error getting cities: %v\n", err)
+	}
+	if len(docs) == 0 {
+		return
+	}
+	last := docs[len(docs)-1].Data()
+
+	// Pass one cursor value per OrderBy clause, in the same order. A tie on
+	// state alone would not identify a unique position in the results, which
+	// is why the population value is needed too. Passing values in a
+	// different order or number than the OrderBy clauses produces an error.
+	next, err := query.StartAfter(last["state"], last["population"]).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		log.Fatalf("error getting next page: %v\n", err)
+	}
+	for _, doc := range next {
+		log.Printf("%s => %v\n", doc.Ref.ID, doc.Data())
+	}
+	// [END fs_multifield_cursor]
+}
+
+func serializeQuery(ctx context.Context, client *firestore.Client) {
+	// [START fs_serialize_query]
+	query := client.CollectionGroup("landmarks").
+		Where("type", "==", "museum").
+		OrderBy("name", firestore.Asc).
+		Limit(10)
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		log.Fatalf("error getting landmarks: %v\n", err)
+	}
+	if len(docs) == 0 {
+		return
+	}
+
+	// Serialize the query for the next page, including its cursor, and hand
+	// it to the caller as an opaque page token.
+	next, err := query.StartAfter(docs[len(docs)-1]).Serialize()
+	if err != nil {
+		log.Fatalf("error serializing query: %v\n", err)
+	}
+	pageToken := base64.URLEncoding.EncodeToString(next)
+
+	// Later, possibly in another process or request, rebuild the query from
+	// the token. Deserialize only uses its receiver for the client, so any
+	// query from the same client works.
+	b, err := base64.URLEncoding.DecodeString(pageToken)
+	if err != nil {
+		log.Fatalf("error decoding page token: %v\n", err)
+	}
+	resumed, err := client.CollectionGroup("landmarks").Deserialize(b)
+	if err != nil {
+		log.Fatalf("error deserializing query: %v\n", err)
+	}
+	docs, err = resumed.Documents(ctx).GetAll()
+	if err != nil {
+		log.Fatalf("error getting landmarks: %v\n", err)
+	}
+	for _, doc := range docs {
+		log.Printf("%s => %v\n", doc.Ref.ID, doc.Data())
+	}
+	// [END fs_serialize_query]
+}
+
+func timeBucketedQuery(ctx context.Context, client *firestore.Client) {
+	// [START fs_time_range_query]
+	events := client.Collection("events")
+
+	// Store times as time.Time values, which become Firestore timestamps.
+	// Firestore keeps microsecond precision, so the nanoseconds of a Go time
+	// are truncated when it is written.
+	_, _, err := events.Add(ctx, map[string]interface{}{
+		"type":      "login",
+		"createdAt": time.Now(),
+	})
+	if err != nil {
+		log.Fatalf("error adding event: %v\n", err)
+	}
+
+	// Query a half-open range [start, end), so consecutive ranges such as
+	// days never overlap or skip a document. A range on a single field uses
+	// the automatic single-field index. Adding an equality filter on another
+	// field (e.g. Where("type", "==", "login")) needs a composite index on
+	// type and createdAt.
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+	iter := events.Where("createdAt", ">=", start).
+		Where("createdAt", "<", end).
+		OrderBy("createdAt", firestore.Asc).
+		Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Fatalf("error querying events: %v\n", err)
+		}
+		log.Printf("%s => %v\n", doc.Ref.ID, doc.Data())
+	}
+	// [END fs_time_range_query]
+}
+
+func unaryFilterQuery(ctx context.Context, client *firestore.Client) {
+	// [START fs_unary_filter]
+	cities := client.Collection("cities")
+
+	// Comparing with nil becomes an "is null" or "is not null" filter.
+	isNull := firestore.PropertyFilter{Path: "mayor", Operator: "==", Value: nil}
+	notNull := firestore.PropertyFilter{Path: "mayor", Operator: "!=", Value: nil}
+
+	// Firestore only indexes fields that are present, and every filter is
+	// served from an index. A document without a mayor field is therefore
+	// matched by neither query: "!= nil" means "has a mayor field with a
+	// non-null value", not "is not null". To find documents missing the
+	// field, query all of them and check with DataAt, or write an explicit
+	// null so the field is always present.
+	for _, f := range []firestore.EntityFilter{isNull, notNull} {
+		docs, err := cities.WhereEntity(f).Documents(ctx).GetAll()
+		if err != nil {
+			log.Fatalf("error querying cities: %v\n", err)
+		}
+		for _, doc := range docs {
+			log.Printf("%s => %v\n", doc.Ref.ID, doc.Data())
+		}
+	}
+	// [END fs_unary_filter]
+}
+
+// ==================================================================
+// https://firebase.google.com/docs/firestore/manage-data/delete-data
+// ==================================================================
+
+func deleteField(ctx context.Context, client *firestore.Client) {
+	// [START fs_delete_field]
+	doc := client.Collection("cities").Doc("BJ")
+
+	// firestore.Delete removes the field from the document entirely.
+	_, err := doc.Update(ctx, []firestore.Update{
+		{Path: "capital", Value: firestore.Delete},
+	})
+	if err != nil {
+		log.Fatalf("error deleting field: %v\n", err)
+	}
+
+	// Writing nil keeps the field, but sets its value to null.
+	_, err = doc.Update(ctx, []firestore.Update{
+		{Path: "capital", Value: nil},
+	})
+	if err != nil {
+		log.Fatalf("error updating field: %v\n", err)
+	}
+	// [END fs_delete_field]
+}
+
+func clearDocumentFields(ctx context.Context, client *firestore.Client) {
+	// [START fs_clear_vs_delete]
+	doc := client.Collection("cities").Doc("DC")
+
+	// Setting an empty map removes every field but keeps the document: Get
+	// returns a snapshot that exists and has no data.
+	if _, err := doc.Set(ctx, map[string]interface{}{}); err != nil {
+		log.Fatalf("error clearing document: %v\n", err)
+	}
+
+	// Delete removes the document itself, but not its subcollections.
+	if _, err := doc.Delete(ctx); err != nil {
+		log.Fatalf("error deleting document: %v\n", err)
+	}
+
+	// A document that was deleted, or that only ever existed as the parent of
+	// a subcollection, is missing: Get returns a NotFound error, even though
+	// queries on its subcollections still return results.
+	_, err := doc.Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		log.Println("DC does not exist")
+	}
+	// [END fs_clear_vs_delete]
+}
+
+// ==================================================================
+// https://firebase.google.com/docs/firestore/manage-data/transactions
+// ==================================================================
+
+func bulkImport(ctx context.Context, client *firestore.Client, records []map[string]interface{}) {
+	// [START fs_bulk_import]
+	// A batch can contain at most 500 writes, so split the records into
+	// chunks and commit each chunk as a separate batch.
+	const maxBatchSize = 500
+	col := client.Collection("cities")
+	total := 0
+	for start := 0; start < len(records); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(records) {
+			end = len(records)
+		}
+
+		batch := client.Batch()
+		for _, record := range records[start:end] {
+			batch.Set(col.NewDoc(), record)
+		}
+		if _, err := batch.Commit(ctx); err != nil {
+			log.Fatalf("error committing batch: %v\n", err)
+		}
+		total += end - start
+	}
+	log.Printf("Imported %d documents\n", total)
+
+	// Batches are committed one at a time and are atomic. When atomicity is
+	// not needed, use a BulkWriter instead. It sends writes in parallel,
+	// retries failed writes, and throttles itself, which gives much higher
+	// throughput:
+	//
+	//	bw := client.BulkWriter(ctx)
+	//	for _, record := range records {
+	//		bw.Set(col.NewDoc(), record)
+	//	}
+	//	bw.End()
+	// [END fs_bulk_import]
+}
+
+func bulkWriterExample(ctx context.Context, client *firestore.Client) {
+	// [START fs_bulkwriter]
+	// A BulkWriter groups writes into batches automatically, sends them in
+	// parallel, and retries writes that fail with retryable errors. Unlike a
+	// Batch, it has no size limit and its writes are not atomic.
+	bw := client.BulkWriter(ctx)
+
+	var jobs []*firestore.BulkWriterJob
+	for i := 0; i < 1000; i++ {
+		doc := client.Collection("cities").Doc(fmt.Sprintf("city-%d", i))
+		job, err := bw.Set(doc, map[string]interface{}{"index": i})
+		if err != nil {
+			log.Fatalf("error enqueuing write: %v\n", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	// End flushes all pending writes and waits for them to complete. The
+	// BulkWriter cannot be used afterwards.
+	bw.End()
+
+	for _, job := range jobs {
+		// Results blocks until the write has completed.
+		result, err := job.Results()
+		if err != nil {
+			log.Printf("write failed: %v\n", err)
+			continue
+		}
+		log.Printf("write completed at %v\n", result.UpdateTime)
+	}
+	// [END fs_bulkwriter]
+}
+
+func transferWithGuard(ctx context.Context, client *firestore.Client) {
+	// [START fs_transaction_abort]
+	from := client.Collection("accounts").Doc("alice")
+	to := client.Collection("accounts").Doc("bob")
+	const amount = 100
+
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		fromSnap, err := tx.Get(from)
+		if err != nil {
+			return err
+		}
+		data, err := fromSnap.DataAt("balance")
+		if err != nil {
+			return err
+		}
+		// Integers are decoded as int64. A balance stored as another type,
+		// such as a double, fails the two-value type assertion instead of
+		// panicking.
+		balance, ok := data.(int64)
+		if !ok {
+			return fmt.Errorf("balance of %s is not an integer", from.ID)
+		}
+		// Returning an error aborts the transaction, and none of its writes
+		// are applied. Only errors from Firestore itself cause a retry.
+		if balance < amount {
+			return errors.New("insufficient funds")
+		}
+
+		if err := tx.Update(from, []firestore.Update{
+			{Path: "balance", Value: firestore.Increment(-amount)},
+		}); err != nil {
+			return err
+		}
+		return tx.Update(to, []firestore.Update{
+			{Path: "balance", Value: firestore.Increment(amount)},
+		})
+	})
+	if err != nil {
+		log.Printf("transfer failed: %v\n", err)
+		return
+	}
+	log.Println("transfer succeeded")
+	// [END fs_transaction_abort]
+}
+
+func transactionGetAll(ctx context.Context, client *firestore.Client) {
+	// [START fs_transaction_getall]
+	cities := client.Collection("cities")
+	refs := []*firestore.DocumentRef{
+		cities.Doc("SF"),
+		cities.Doc("LA"),
+		cities.Doc("DC"),
+	}
+
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		// Read all of the documents in one round trip instead of one Get per
+		// document. As with Get, every read must happen before the first
+		// write in the transaction.
+		docs, err := tx.GetAll(refs)
+		if err != nil {
+			return err
+		}
+
+		var total int64
+		for _, doc := range docs {
+			if !doc.Exists() {
+				return fmt.Errorf("city %s does not exist", doc.Ref.ID)
+			}
+			data, err := doc.DataAt("population")
+			if err != nil {
+				return err
+			}
+			pop, ok := data.(int64)
+			if !ok {
+				return fmt.Errorf("population of %s is not an integer", doc.Ref.ID)
+			}
+			total += pop
+		}
+
+		return tx.Set(client.Collection("stats").Doc("cities"), map[string]interface{}{
+			"totalPopulation": total,
+		})
+	})
+	if err != nil {
+		log.Fatalf("error running transaction: %v\n", err)
+	}
+	// [END fs_transaction_getall]
+}
+
+func optimisticUpdate(ctx context.Context, client *firestore.Client) {
+	// [START fs_optimistic_update]
+	doc := client.Collection("cities").Doc("SF")
+	snap, err := doc.Get(ctx)
+	if err != nil {
+		log.Fatalf("error getting city: %v\n", err)
+	}
+	data, err := snap.DataAt("population")
+	if err != nil {
+		log.Fatalf("error reading population: %v\n", err)
+	}
+	pop, ok := data.(int64)
+	if !ok {
+		log.Fatalf("population of %s is not an integer\n", doc.ID)
+	}
+
+	// The write only succeeds if the document's update time still matches
+	// the snapshot, i.e. nobody has written it since it was read. Update and
+	// Delete accept preconditions; Set does not.
+	_, err = doc.Update(ctx, []firestore.Update{
+		{Path: "population", Value: pop + 1},
+	}, firestore.LastUpdateTime(snap.UpdateTime))
+	if status.Code(err) == codes.FailedPrecondition {
+		// The document changed in the meantime. Read it again and reapply
+		// the change, or report the conflict to the user.
+		log.Println("SF was modified concurrently; retry")
+		return
+	}
+	if err != nil {
+		log.Fatalf("error updating city: %v\n", err)
+	}
+	// [END fs_optimistic_update]
+}
+
+// ==================================================================
+// https://firebase.google.com/docs/firestore/manage-data/add-data
+// ==================================================================
+
+func createDocumentOnce(ctx context.Context, client *firestore.Client) {
+	// [START fs_create_once]
+	data := map[string]interface{}{
+		"name":  "Los Angeles",
+		"state": "CA",
+	}
+
+	// Create fails if the document already exists, unlike Set, which
+	// overwrites it. This is how to insert a document only if it is missing.
+	_, err := client.Collection("cities").Doc("LA").Create(ctx, data)
+	if status.Code(err) == codes.AlreadyExists {
+		log.Println("document cities/LA already exists")
+		return
+	}
+	if err != nil {
+		log.Fatalf("error creating document: %v\n", err)
+	}
+	log.Println("created document cities/LA")
+	// [END fs_create_once]
+}
+
+func writeWithSizeCheck(ctx context.Context, client *firestore.Client, data map[string]interface{}) error {
+	// [START fs_size_limit]
+	// A document can be at most 1 MiB (1,048,576 bytes). The stored size is
+	// roughly the size of the field names and values (strings count their
+	// UTF-8 bytes plus one, numbers 8 bytes), plus the document name. The
+	// JSON encoding of the data is a quick approximation to reject obviously
+	// oversized documents before sending them.
+	const maxDocumentSize = 1 << 20
+	if encoded, err := json.Marshal(data); err == nil && len(encoded) > maxDocumentSize {
+		return fmt.Errorf("document is about %d bytes, over the 1 MiB limit", len(encoded))
+	}
+
+	_, err := client.Collection("reports").Doc("large").Set(ctx, data)
+	if status.Code(err) == codes.InvalidArgument {
+		// Firestore rejects documents over the limit with InvalidArgument.
+		// Split large arrays into a subcollection or move blobs to Cloud
+		// Storage instead.
+		return fmt.Errorf("document was rejected, it may be too large: %v", err)
+	}
+	if err != nil {
+		return err
+	}
+	// [END fs_size_limit]
+
+	return nil
+}
+
+func updateNestedMap(ctx context.Context, client *firestore.Client) {
+	// [START fs_update_nested_map]
+	// Suppose the document contains:
+	//   settings: {notifications: true, theme: "dark"}
+	doc := client.Collection("users").Doc("frank")
+
+	// A dotted path updates only that key of the nested map. The theme key
+	// is left as it is.
+	_, err := doc.Update(ctx, []firestore.Update{
+		{Path: "settings.notifications", Value: false},
+	})
+	if err != nil {
+		log.Fatalf("error updating settings: %v\n", err)
+	}
+	// settings: {notifications: false, theme: "dark"}
+
+	// Updating the map field itself replaces the whole map, so the theme key
+	// is removed.
+	_, err = doc.Update(ctx, []firestore.Update{
+		{Path: "settings", Value: map[string]interface{}{"notifications": false}},
+	})
+	if err != nil {
+		log.Fatalf("error updating settings: %v\n", err)
+	}
+	// settings: {notifications: false}
+	// [END fs_update_nested_map]
+}
+
+func addAndReadBack(ctx context.Context, client *firestore.Client) {
+	// [START fs_add_read_back]
+	data := map[string]interface{}{
+		"title":     "Hello, Firestore",
+		"createdAt": firestore.ServerTimestamp,
+	}
+	// Add generates the document ID on the client and returns the new ref.
+	ref, _, err := client.Collection("posts").Add(ctx, data)
+	if err != nil {
+		log.Fatalf("error adding post: %v\n", err)
+	}
+	log.Printf("Added post with ID: %s\n", ref.ID)
+
+	// The ServerTimestamp sentinel is replaced by the server when the write
+	// is committed; the local map still holds the sentinel, not a time. Read
+	// the document back to get the stored value.
+	snap, err := ref.Get(ctx)
+	if err != nil {
+		log.Fatalf("error reading post %s: %v\n", ref.ID, err)
+	}
+	createdAt := snap.Data()["createdAt"].(time.Time)
+	log.Printf("Post %s created at %v\n", ref.ID, createdAt)
+	// [END fs_add_read_back]
+}
+
+func retryOnAborted(ctx context.Context, client *firestore.Client) {
+	// [START fs_retry_aborted]
+	doc := client.Collection("cities").Doc("SF")
+	data := map[string]interface{}{
+		"name":       "San Francisco",
+		"population": 860000,
+	}
+
+	// Aborted (contention with another write) and Unavailable (a transient
+	// server or network failure) are safe to retry. Unavailable can be
+	// returned after the write was applied, so only retry writes that are
+	// idempotent, like this Set. Don't blindly retry non-idempotent writes
+	// such as Increment, and don't retry errors like InvalidArgument or
+	// PermissionDenied, which fail the same way every time.
+	const maxAttempts = 5
+	backoff := 100 * time.Millisecond
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		_, err = doc.Set(ctx, data)
+		code := status.Code(err)
+		if (code != codes.Aborted && code != codes.Unavailable) || attempt == maxAttempts {
+			break
+		}
+		log.Printf("attempt %d failed with %v; retrying in %v\n", attempt, code, backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	if err != nil {
+		log.Fatalf("error writing document: %v\n", err)
+	}
+	// [END fs_retry_aborted]
+}
+
+// ==================================================================
+// https://firebase.google.com/docs/firestore/query-data/get-data
+// ==================================================================
+
+func streamWithTimeout(ctx context.Context, client *firestore.Client) {
+	// [START fs_stream_timeout]
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	iter := client.Collection("cities").Documents(ctx)
+	// A DocumentIterator releases its resources once Next returns
+	// iterator.Done or an error, but leaving the loop early (as below) leaks
+	// them unless Stop is called. Deferring Stop covers both cases. Snapshot
+	// iterators from Snapshots never return iterator.Done, so they must
+	// always be stopped explicitly.
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Println("deadline exceeded; stopping early")
+			break
+		}
+		if err != nil {
+			log.Fatalf("error reading cities: %v\n", err)
+		}
+		log.Printf("%s => %v\n", doc.Ref.ID, doc.Data())
+	}
+	// [END fs_stream_timeout]
+}
+
+func listRootCollections(ctx context.Context, client *firestore.Client) {
+	// [START fs_list_root_collections]
+	iter := client.Collections(ctx)
+	for {
+		col, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Fatalf("error listing collections: %v\n", err)
+		}
+		log.Printf("Found collection with id: %s\n", col.ID)
+	}
+	// [END fs_list_root_collections]
+}
+
+func getAllByRefs(ctx context.Context, client *firestore.Client, ids []string) []*firestore.DocumentSnapshot {
+	// [START fs_getall]
+	refs := make([]*firestore.DocumentRef, len(ids))
+	for i, id := range ids {
+		refs[i] = client.Collection("cities").Doc(id)
+	}
+
+	// GetAll fetches all the documents in a single round trip, instead of one
+	// round trip per Get call. Snapshots are returned in the same order as
+	// the refs, and missing documents are returned as snapshots that do not
+	// exist.
+	snaps, err := client.GetAll(ctx, refs)
+	if err != nil {
+		log.Fatalf("error getting documents: %v\n", err)
+	}
+	for _, snap := range snaps {
+		if !snap.Exists() {
+			log.Printf("%s does not exist\n", snap.Ref.ID)
+			continue
+		}
+		log.Printf("%s => %v\n", snap.Ref.ID, snap.Data())
+	}
+	// [END fs_getall]
+
+	return snaps
+}
+
+func decodeNestedStruct(ctx context.Context, client *firestore.Client) {
+	// [START fs_nested_decode]
+	type Address struct {
+		Street string `firestore:"street"`
+		City   string `firestore:"city"`
+	}
+	type Store struct {
+		Name string `firestore:"name"`
+		// A nested map field in the document can decode into a struct
+		// (matched field by field) or into a map (every key is kept).
+		Address *Address       `firestore:"address"`
+		Stock   map[string]int `firestore:"stock"`
+	}
+
+	snap, err := client.Collection("stores").Doc("downtown").Get(ctx)
+	if err != nil {
+		log.Fatalf("error getting store: %v\n", err)
+	}
+	var store Store
+	if err := snap.DataTo(&store); err != nil {
+		log.Fatalf("error decoding store: %v\n", err)
+	}
+
+	// Fields missing from the document are left untouched, so the pointer
+	// stays nil when the document has no address field.
+	if store.Address == nil {
+		log.Printf("%s has no address\n", store.Name)
+	} else {
+		log.Printf("%s is at %s, %s\n", store.Name, store.Address.Street, store.Address.City)
+	}
+	for item, count := range store.Stock {
+		log.Printf("%s: %d\n", item, count)
+	}
+	// [END fs_nested_decode]
+}
+
+func decodeTimestamps(ctx context.Context, client *firestore.Client) {
+	// [START fs_decode_timestamps]
+	// A Firestore timestamp decodes into a time.Time, a *time.Time or a
+	// *timestamppb.Timestamp. Other types, such as int64 or string, are not
+	// converted, and DataTo returns an error.
+	type Event struct {
+		Name      string                 `firestore:"name"`
+		StartsAt  time.Time              `firestore:"startsAt"`
+		EndsAt    *time.Time             `firestore:"endsAt"`
+		UpdatedAt *timestamppb.Timestamp `firestore:"updatedAt"`
+	}
+
+	snap, err := client.Collection("events").Doc("launch").Get(ctx)
+	if err != nil {
+		log.Fatalf("error getting event: %v\n", err)
+	}
+	var event Event
+	if err := snap.DataTo(&event); err != nil {
+		log.Fatalf("error decoding event: %v\n", err)
+	}
+	log.Printf("%s starts at %v\n", event.Name, event.StartsAt)
+
+	// Without a struct, Data returns timestamps as time.Time values. Use the
+	// two-value type assertion in case the field is missing or has another
+	// type.
+	startsAt, ok := snap.Data()["startsAt"].(time.Time)
+	if !ok {
+		log.Fatalln("startsAt is not a timestamp")
+	}
+	log.Printf("starts at %v\n", startsAt)
+	// [END fs_decode_timestamps]
+}
+
+func queryWithPager(ctx context.Context, client *firestore.Client) {
+	// [START fs_query_pager]
+	// Like the Users iterator in the list_all_users snippet, the iterator
+	// returned by DocumentRefs supports iterator.Pager and page tokens. The
+	// DocumentIterator returned by Documents for a query does not; page
+	// through query results with Limit and StartAfter instead.
+	pager := iterator.NewPager(client.Collection("cities").DocumentRefs(ctx), 20, "")
+	for {
+		var refs []*firestore.DocumentRef
+		nextPageToken, err := pager.NextPage(&refs)
+		if err != nil {
+			log.Fatalf("paging error %v\n", err)
+		}
+		// DocumentRefs also lists missing documents that only have
+		// subcollections. Fetch the page's documents in one call.
+		docs, err := client.GetAll(ctx, refs)
+		if err != nil {
+			log.Fatalf("error getting cities: %v\n", err)
+		}
+		for _, doc := range docs {
+			if doc.Exists() {
+				log.Printf("%s => %v\n", doc.Ref.ID, doc.Data())
+			}
+		}
+		if nextPageToken == "" {
+			break
+		}
+	}
+	// [END fs_query_pager]
+}
+
+// ==================================================================
+// https://firebase.google.com/docs/firestore/query-data/listen
+// ==================================================================
+
+func listenWithInitialSnapshot(ctx context.Context, client *firestore.Client) {
+	// [START fs_listen_initial]
+	iter := client.Collection("cities").Where("state", "==", "CA").Snapshots(ctx)
+	defer iter.Stop()
+
+	// The first snapshot contains the full result set, reported as one
+	// DocumentAdded change per document. Every later snapshot only lists
+	// what changed since the previous one.
+	initial := true
+	for {
+		snap, err := iter.Next()
+		if status.Code(err) == codes.Canceled || status.Code(err) == codes.DeadlineExceeded {
+			return
+		}
+		if err != nil {
+			log.Fatalf("error listening to cities: %v\n", err)
+		}
+
+		if initial {
+			log.Printf("initial load: %d cities\n", snap.Size)
+			initial = false
+			continue
+		}
+		for _, change := range snap.Changes {
+			switch change.Kind {
+			case firestore.DocumentAdded:
+				log.Printf("New city: %v\n", change.Doc.Data())
+			case firestore.DocumentModified:
+				log.Printf("Modified city: %v\n", change.Doc.Data())
+			case firestore.DocumentRemoved:
+				log.Printf("Removed city: %v\n", change.Doc.Data())
+			}
+		}
+	}
+	// [END fs_listen_initial]
+}
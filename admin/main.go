@@ -17,11 +17,18 @@ package main
 // [START admin_import]
 import (
 	"context"
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
 
-	firebase "firebase.google.com/go"
-	"firebase.google.com/go/auth"
+	firebase "firebase.google.com/go/v4"
+	"firebase.google.com/go/v4/auth"
 
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
@@ -58,6 +65,7 @@ func initializeAppWithRefreshToken() *firebase.App {
 }
 
 func initializeAppDefault() *firebase.App {
+	// See initializeAppWithEnvVar for how credentials are discovered.
 	// [START initialize_app_default]
 	app, err := firebase.NewApp(context.Background(), nil)
 	if err != nil {
@@ -68,6 +76,40 @@ func initializeAppDefault() *firebase.App {
 	return app
 }
 
+func initializeAppWithEnvVar() *firebase.App {
+	// [START initialize_app_env_var]
+	// With no options, the SDK looks up Application Default Credentials in
+	// the following order:
+	//   1. The JSON key file named by the GOOGLE_APPLICATION_CREDENTIALS
+	//      environment variable.
+	//   2. The credentials written by `gcloud auth application-default login`.
+	//   3. The metadata server, when running on Google Cloud.
+	//
+	// export GOOGLE_APPLICATION_CREDENTIALS="/path/to/serviceAccountKey.json"
+	app, err := firebase.NewApp(context.Background(), nil)
+	if err != nil {
+		log.Fatalf("error initializing app: %v\n", err)
+	}
+	// [END initialize_app_env_var]
+
+	return app
+}
+
+func initializeAppFromJSON() *firebase.App {
+	// [START initialize_app_from_json]
+	// Read the service account key from an environment variable populated by
+	// your secret manager, instead of from a file on disk.
+	jsonBytes := []byte(os.Getenv("FIREBASE_SERVICE_ACCOUNT_JSON"))
+	opt := option.WithCredentialsJSON(jsonBytes)
+	app, err := firebase.NewApp(context.Background(), nil, opt)
+	if err != nil {
+		log.Fatalf("error initializing app: %v\n", err)
+	}
+	// [END initialize_app_from_json]
+
+	return app
+}
+
 func accessServicesSingleApp() (*auth.Client, error) {
 	// [START access_services_single_app]
 	// Initialize default app
@@ -118,6 +160,89 @@ func accessServicesMultipleApp() (*auth.Client, error) {
 	return otherClient, nil
 }
 
+func initializeNamedApp() *firebase.App {
+	// [START initialize_named_app]
+	// Unlike the Node.js and Java SDKs, the Go SDK has no global app registry
+	// and NewApp takes no app name. Each call returns an independent *App, so
+	// keep track of them yourself, for example in a map keyed by name.
+	apps := map[string]*firebase.App{}
+
+	defaultApp, err := firebase.NewApp(context.Background(), nil)
+	if err != nil {
+		log.Fatalf("error initializing app: %v\n", err)
+	}
+	apps["default"] = defaultApp
+
+	opt := option.WithCredentialsFile("service-account-other.json")
+	otherApp, err := firebase.NewApp(context.Background(), nil, opt)
+	if err != nil {
+		log.Fatalf("error initializing app: %v\n", err)
+	}
+	apps["other"] = otherApp
+
+	// Look up the app by name wherever it is needed.
+	app, ok := apps["other"]
+	if !ok {
+		log.Fatalln("no app named other")
+	}
+	// [END initialize_named_app]
+
+	return app
+}
+
+func initializeAppCustomHTTPClient() *firebase.App {
+	// [START initialize_custom_http_client]
+	ctx := context.Background()
+
+	// An injected HTTP client is used as-is, and any other credential options
+	// are ignored. The client must therefore attach credentials itself.
+	creds, err := google.FindDefaultCredentials(ctx,
+		"https://www.googleapis.com/auth/cloud-platform",
+		"https://www.googleapis.com/auth/firebase",
+		"https://www.googleapis.com/auth/userinfo.email")
+	if err != nil {
+		log.Fatalf("error finding credentials: %v\n", err)
+	}
+
+	hc := &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &oauth2.Transport{Source: creds.TokenSource},
+	}
+
+	// The HTTP client is used by the Auth, Messaging, Realtime Database, and
+	// Cloud Storage clients. Firestore talks gRPC and rejects an HTTP client,
+	// so app.Firestore fails on this app. Initialize a separate app without
+	// this option for Firestore.
+	config := &firebase.Config{ProjectID: "my-project-id"}
+	app, err := firebase.NewApp(ctx, config, option.WithHTTPClient(hc))
+	if err != nil {
+		log.Fatalf("error initializing app: %v\n", err)
+	}
+	// [END initialize_custom_http_client]
+
+	return app
+}
+
+func initializeAppInCloudRun() *firebase.App {
+	// [START initialize_app_cloud_run]
+	// On Cloud Functions, Cloud Run, App Engine, and GCE, credentials come
+	// from the metadata server and the project ID from the environment (for
+	// example GOOGLE_CLOUD_PROJECT), so no service account file is needed.
+	// Service-specific settings are not discovered, though, and must still be
+	// set explicitly.
+	config := &firebase.Config{
+		DatabaseURL:   "https://<DATABASE_NAME>.firebaseio.com",
+		StorageBucket: "<BUCKET_NAME>.appspot.com",
+	}
+	app, err := firebase.NewApp(context.Background(), config)
+	if err != nil {
+		log.Fatalf("error initializing app: %v\n", err)
+	}
+	// [END initialize_app_cloud_run]
+
+	return app
+}
+
 // ==================================================================
 // https://firebase.google.com/docs/auth/admin/create-custom-tokens
 // ==================================================================
@@ -129,7 +254,7 @@ func createCustomToken(app *firebase.App) string {
 		log.Fatalf("error getting Auth client: %v\n", err)
 	}
 
-	token, err := client.CustomToken("some-uid")
+	token, err := client.CustomToken(context.Background(), "some-uid")
 	if err != nil {
 		log.Fatalf("error minting custom token: %v\n", err)
 	}
@@ -151,7 +276,7 @@ func createCustomTokenWithClaims(app *firebase.App) string {
 		"premiumAccount": true,
 	}
 
-	token, err := client.CustomTokenWithClaims("some-uid", claims)
+	token, err := client.CustomTokenWithClaims(context.Background(), "some-uid", claims)
 	if err != nil {
 		log.Fatalf("error minting custom token: %v\n", err)
 	}
@@ -162,6 +287,69 @@ func createCustomTokenWithClaims(app *firebase.App) string {
 	return token
 }
 
+func createTenantCustomToken(ctx context.Context, tenantClient *auth.TenantClient, uid string) string {
+	// [START tenant_custom_token]
+	// Tokens minted by a tenant client carry the tenant ID in their claims.
+	// They can only be used to sign in to that tenant, not to the default
+	// project-level user pool or to any other tenant.
+	token, err := tenantClient.CustomToken(ctx, uid)
+	if err != nil {
+		log.Fatalf("error minting custom token: %v\n", err)
+	}
+
+	log.Printf("Got custom token for tenant %s: %v\n", tenantClient.TenantID(), token)
+	// [END tenant_custom_token]
+
+	return token
+}
+
+func mintCustomTokenForExchange(ctx context.Context, client *auth.Client, uid string) string {
+	// [START custom_token_exchange]
+	token, err := client.CustomToken(ctx, uid)
+	if err != nil {
+		log.Fatalf("error minting custom token: %v\n", err)
+	}
+
+	// Return the token to the client app, which signs in with it, for example
+	// with signInWithCustomToken() in the client SDKs, or over REST:
+	//
+	//   POST https://identitytoolkit.googleapis.com/v1/accounts:signInWithCustomToken?key=[API_KEY]
+	//   {"token": "<custom token>", "returnSecureToken": true}
+	//
+	// The response contains an ID token and a refresh token for the user. The
+	// client sends the ID token to your backend, which verifies it with
+	// VerifyIDToken. The custom token itself expires after one hour.
+	log.Printf("Got custom token: %v\n", token)
+	// [END custom_token_exchange]
+
+	return token
+}
+
+func customTokenReservedClaims(ctx context.Context, client *auth.Client) {
+	// [START custom_token_reserved]
+	// Claims that are part of the JWT and OIDC specifications, or used by
+	// Firebase, cannot be set as developer claims: acr, amr, at_hash, aud,
+	// auth_time, azp, cnf, c_hash, exp, firebase, iat, iss, jti, nbf, nonce,
+	// and sub. The SDK rejects them before minting the token.
+	claims := map[string]interface{}{
+		"sub": "someone-else",
+	}
+	if _, err := client.CustomTokenWithClaims(ctx, "some-uid", claims); err != nil {
+		log.Printf("error minting custom token: %v\n", err)
+	}
+
+	// Use a name of your own instead.
+	claims = map[string]interface{}{
+		"delegatedFor": "someone-else",
+	}
+	token, err := client.CustomTokenWithClaims(ctx, "some-uid", claims)
+	if err != nil {
+		log.Fatalf("error minting custom token: %v\n", err)
+	}
+	log.Printf("Got custom token: %v\n", token)
+	// [END custom_token_reserved]
+}
+
 // ==================================================================
 // https://firebase.google.com/docs/auth/admin/verify-id-tokens
 // ==================================================================
@@ -173,7 +361,7 @@ func verifyIDToken(app *firebase.App, idToken string) *auth.Token {
 		log.Fatalf("error getting Auth client: %v\n", err)
 	}
 
-	token, err := client.VerifyIDToken(idToken)
+	token, err := client.VerifyIDToken(context.Background(), idToken)
 	if err != nil {
 		log.Fatalf("error verifying ID token: %v\n", err)
 	}
@@ -184,6 +372,74 @@ func verifyIDToken(app *firebase.App, idToken string) *auth.Token {
 	return token
 }
 
+func verifyTokenForTenant(ctx context.Context, client *auth.Client, idToken, expectedTenant string) (*auth.Token, error) {
+	// [START verify_token_tenant]
+	token, err := client.VerifyIDToken(ctx, idToken)
+	if err != nil {
+		return nil, fmt.Errorf("error verifying ID token: %v", err)
+	}
+
+	// The tenant the user signed in to is in the firebase.tenant claim of the
+	// ID token, which is decoded into token.Firebase.Tenant. It is empty for
+	// users of the default project-level user pool.
+	if token.Firebase.Tenant != expectedTenant {
+		return nil, fmt.Errorf("token belongs to tenant %q, want %q",
+			token.Firebase.Tenant, expectedTenant)
+	}
+	log.Printf("Verified ID token for tenant %s: %v\n", expectedTenant, token)
+	// [END verify_token_tenant]
+
+	return token, nil
+}
+
+func getSignInProvider(ctx context.Context, client *auth.Client, idToken string) string {
+	// [START get_signin_provider]
+	token, err := client.VerifyIDToken(ctx, idToken)
+	if err != nil {
+		log.Fatalf("error verifying ID token: %v\n", err)
+	}
+
+	// The provider used for this sign-in, such as "google.com", "password",
+	// "phone", "anonymous" or "custom".
+	provider := token.Firebase.SignInProvider
+	switch provider {
+	case "password":
+		log.Println("User signed in with email and password")
+	case "phone":
+		log.Println("User signed in with a phone number")
+	default:
+		log.Printf("User signed in with %s\n", provider)
+	}
+
+	// Identities maps each linked provider to the user's identifiers with
+	// that provider, e.g. "google.com": ["1234567890"] or
+	// "email": ["user@example.com"].
+	for providerID, ids := range token.Firebase.Identities {
+		log.Printf("%s: %v\n", providerID, ids)
+	}
+	// [END get_signin_provider]
+
+	return provider
+}
+
+func maybeTenantFromToken(token *auth.Token) (string, bool) {
+	// [START token_tenant_optional]
+	// Users who sign in to the project-level user pool, rather than to a
+	// tenant, get ID tokens without a firebase.tenant claim, so
+	// token.Firebase.Tenant is empty. A project that doesn't use
+	// multi-tenancy never sees a tenant, so don't treat an empty value as an
+	// error unless your app only accepts tenant users.
+	tenantID := token.Firebase.Tenant
+	if tenantID == "" {
+		log.Printf("User %s signed in without a tenant\n", token.UID)
+		return "", false
+	}
+	log.Printf("User %s signed in to tenant %s\n", token.UID, tenantID)
+	// [END token_tenant_optional]
+
+	return tenantID, true
+}
+
 // ==================================================================
 // https://firebase.google.com/docs/auth/admin/manage-sessions
 // ==================================================================
@@ -253,6 +509,25 @@ func getUser(ctx context.Context, app *firebase.App) *auth.UserRecord {
 	return u
 }
 
+func getUserSafe(ctx context.Context, client *auth.Client, uid string) (*auth.UserRecord, bool, error) {
+	// [START get_user_not_found]
+	u, err := client.GetUser(ctx, uid)
+	if auth.IsUserNotFound(err) {
+		// Not an error condition for the service. Report that the user is
+		// missing with the found flag, so callers never get a nil record
+		// together with a nil error.
+		log.Printf("no such user: %s\n", uid)
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("error getting user %s: %v", uid, err)
+	}
+	log.Printf("Successfully fetched user data: %v\n", u)
+	// [END get_user_not_found]
+
+	return u, true, nil
+}
+
 func getUserByEmail(ctx context.Context, client *auth.Client) *auth.UserRecord {
 	email := "some@email.com"
 	// [START get_user_by_email]
@@ -342,6 +617,29 @@ func deleteUser(ctx context.Context, client *auth.Client) {
 	// [END delete_user]
 }
 
+func toggleUserDisabled(ctx context.Context, client *auth.Client, uid string) {
+	// [START toggle_user_disabled]
+	// Disabling a user blocks new sign-ins and token refreshes, but ID tokens
+	// that were already issued stay valid until they expire (up to an hour).
+	// Call RevokeRefreshTokens and verify tokens with
+	// VerifyIDTokenAndCheckRevoked to cut off existing sessions immediately.
+	params := (&auth.UserToUpdate{}).Disabled(true)
+	u, err := client.UpdateUser(ctx, uid, params)
+	if err != nil {
+		log.Fatalf("error disabling user: %v\n", err)
+	}
+	log.Printf("User %s disabled: %v\n", uid, u.Disabled)
+
+	// Re-enable the user.
+	params = (&auth.UserToUpdate{}).Disabled(false)
+	u, err = client.UpdateUser(ctx, uid, params)
+	if err != nil {
+		log.Fatalf("error enabling user: %v\n", err)
+	}
+	log.Printf("User %s disabled: %v\n", uid, u.Disabled)
+	// [END toggle_user_disabled]
+}
+
 func customClaimsSet(ctx context.Context, app *firebase.App) {
 	uid := "uid"
 	// [START set_custom_user_claims]
@@ -367,7 +665,7 @@ func customClaimsVerify(ctx context.Context, client *auth.Client) {
 	idToken := "token"
 	// [START verify_custom_claims]
 	// Verify the ID token first.
-	token, err := client.VerifyIDToken(idToken)
+	token, err := client.VerifyIDToken(ctx, idToken)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -381,6 +679,31 @@ func customClaimsVerify(ctx context.Context, client *auth.Client) {
 	// [END verify_custom_claims]
 }
 
+func readClaimsFromToken(ctx context.Context, client *auth.Client, idToken string) bool {
+	// [START read_token_claims]
+	token, err := client.VerifyIDToken(ctx, idToken)
+	if err != nil {
+		log.Printf("error verifying ID token: %v\n", err)
+		return false
+	}
+
+	// The claim may be missing, or hold a value of an unexpected type. The
+	// two-value type assertion handles both cases without panicking.
+	admin, ok := token.Claims["admin"].(bool)
+	if !ok {
+		log.Printf("user %s has no boolean admin claim\n", token.UID)
+		return false
+	}
+	if !admin {
+		log.Printf("user %s is not an admin\n", token.UID)
+		return false
+	}
+	// Allow access to the requested admin resource.
+	// [END read_token_claims]
+
+	return true
+}
+
 func customClaimsRead(ctx context.Context, client *auth.Client) {
 	uid := "uid"
 	// [START read_custom_user_claims]
@@ -442,6 +765,33 @@ func customClaimsIncremental(ctx context.Context, client *auth.Client) {
 	// [END set_custom_user_claims_incremental]
 }
 
+func setLargeCustomClaims(ctx context.Context, client *auth.Client, uid string) {
+	// [START custom_claims_size]
+	// Custom claims are limited to 1000 bytes once serialized to JSON, and
+	// they are sent along with every ID token. Large payloads are rejected.
+	largeClaims := map[string]interface{}{
+		"permissions": strings.Repeat("x", 1000),
+	}
+	if err := client.SetCustomUserClaims(ctx, uid, largeClaims); err != nil {
+		log.Printf("error setting custom claims: %v\n", err)
+	}
+
+	// Store a short role instead, and keep larger profile data in a database.
+	claims := map[string]interface{}{"role": "admin"}
+	if err := client.SetCustomUserClaims(ctx, uid, claims); err != nil {
+		log.Fatalf("error setting custom claims %v\n", err)
+	}
+
+	u, err := client.GetUser(ctx, uid)
+	if err != nil {
+		log.Fatalf("error getting user %s: %v\n", uid, err)
+	}
+	if role, ok := u.CustomClaims["role"].(string); ok {
+		log.Printf("User %s has role: %s\n", uid, role)
+	}
+	// [END custom_claims_size]
+}
+
 func listUsers(ctx context.Context, client *auth.Client) {
 	// [START list_all_users]
 	// Note, behind the scenes, the Users() iterator will retrive 1000 Users at a time through the API
@@ -477,6 +827,185 @@ func listUsers(ctx context.Context, client *auth.Client) {
 	// [END list_all_users]
 }
 
+func listUsersByProvider(ctx context.Context, client *auth.Client, providerID string) []*auth.ExportedUserRecord {
+	// [START list_users_by_provider]
+	// There is no server-side filter for users, so iterate over all of them
+	// and match the provider in application code. For large user bases, page
+	// through the users in batches as shown in the list_all_users snippet.
+	var matched []*auth.ExportedUserRecord
+	iter := client.Users(ctx, "")
+	for {
+		user, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Fatalf("error listing users: %s\n", err)
+		}
+		for _, info := range user.ProviderUserInfo {
+			if info.ProviderID == providerID {
+				matched = append(matched, user)
+				break
+			}
+		}
+	}
+	log.Printf("found %d users signed in with %s\n", len(matched), providerID)
+	// [END list_users_by_provider]
+
+	return matched
+}
+
+// [START millis_to_time]
+// millisToTime converts a timestamp in milliseconds since the epoch, such as
+// UserMetadata.CreationTimestamp or TokensValidAfterMillis, to a time.Time.
+func millisToTime(millis int64) time.Time {
+	return time.Unix(0, millis*int64(time.Millisecond)).UTC()
+}
+
+// timeToMillis converts a time.Time to milliseconds since the epoch, for
+// APIs that expect timestamps in milliseconds.
+func timeToMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// [END millis_to_time]
+
+func handleEmailEnumeration(ctx context.Context, client *auth.Client, email string) string {
+	// [START email_enumeration]
+	// Email enumeration protection only changes the responses of the client
+	// facing Auth APIs. The Admin SDK runs with privileged credentials, so
+	// GetUserByEmail still reports whether an account exists. Your own
+	// endpoints must not pass that on, or they undo the protection.
+	const response = "If an account exists for this address, you will receive an email shortly."
+
+	u, err := client.GetUserByEmail(ctx, email)
+	if auth.IsUserNotFound(err) {
+		// Respond exactly as if the user had been found.
+		return response
+	}
+	if err != nil {
+		// Log the details internally, but return the same response.
+		log.Printf("error getting user by email %s: %v\n", email, err)
+		return response
+	}
+
+	link, err := client.PasswordResetLink(ctx, u.Email)
+	if err != nil {
+		log.Printf("error generating password reset link: %v\n", err)
+		return response
+	}
+	// Send the link to the user with your own email service.
+	log.Printf("Password reset link: %s\n", link)
+	// [END email_enumeration]
+
+	return response
+}
+
+func markEmailVerified(ctx context.Context, client *auth.Client, uid string) {
+	// [START mark_email_verified]
+	// Only EmailVerified is changed; all other properties are left as is.
+	// This skips Firebase's verification email entirely, so only do it when
+	// you have verified the address some other way, for example through an
+	// external identity provider.
+	params := (&auth.UserToUpdate{}).EmailVerified(true)
+	u, err := client.UpdateUser(ctx, uid, params)
+	if err != nil {
+		log.Fatalf("error updating user: %v\n", err)
+	}
+	log.Printf("Successfully marked email of user %s as verified: %v\n", u.UID, u.EmailVerified)
+	// [END mark_email_verified]
+}
+
+func usersCreatedSince(ctx context.Context, client *auth.Client, since time.Time) []*auth.ExportedUserRecord {
+	// [START users_created_since]
+	// The SDK can't filter users by creation date on the server, so every
+	// user in the project is downloaded and filtered here. On large projects
+	// this takes many requests and counts against the Auth API quota; if you
+	// need this often, record sign-ups in your own database instead.
+	var matched []*auth.ExportedUserRecord
+	pager := iterator.NewPager(client.Users(ctx, ""), 1000, "")
+	for {
+		var users []*auth.ExportedUserRecord
+		nextPageToken, err := pager.NextPage(&users)
+		if err != nil {
+			log.Fatalf("paging error %v\n", err)
+		}
+		for _, u := range users {
+			// CreationTimestamp is in milliseconds since the epoch.
+			created := millisToTime(u.UserMetadata.CreationTimestamp)
+			if !created.Before(since) {
+				matched = append(matched, u)
+			}
+		}
+		if nextPageToken == "" {
+			break
+		}
+	}
+	log.Printf("found %d users created since %v\n", len(matched), since)
+	// [END users_created_since]
+
+	return matched
+}
+
+func mergeCustomClaims(ctx context.Context, client *auth.Client, uid string, add map[string]interface{}) {
+	// [START merge_custom_claims]
+	// SetCustomUserClaims replaces all of the user's claims, so claims set
+	// elsewhere (by another service or an earlier script) are lost unless
+	// they are read first and written back together with the new ones.
+	user, err := client.GetUser(ctx, uid)
+	if err != nil {
+		log.Fatalf("error getting user %s: %v\n", uid, err)
+	}
+	claims := map[string]interface{}{}
+	for k, v := range user.CustomClaims {
+		claims[k] = v
+	}
+	for k, v := range add {
+		claims[k] = v
+	}
+
+	// The read and the write are not atomic. If several processes update
+	// claims for the same user concurrently, route the updates through a
+	// single writer so that one update doesn't overwrite another.
+	if err := client.SetCustomUserClaims(ctx, uid, claims); err != nil {
+		log.Fatalf("error setting custom claims %v\n", err)
+	}
+	// [END merge_custom_claims]
+}
+
+func disableUserEverywhere(ctx context.Context, client *auth.Client, uid string) {
+	// [START disable_user_all_tenants]
+	// Each tenant has its own user pool, and UIDs are only unique within a
+	// pool. The same UID can belong to unrelated users in different tenants,
+	// so only do this when the UID identifies one person across tenants, for
+	// example because your app assigns UIDs itself.
+	params := (&auth.UserToUpdate{}).Disabled(true)
+	iter := client.TenantManager.Tenants(ctx, "")
+	for {
+		tenant, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Fatalf("error listing tenants: %v\n", err)
+		}
+
+		tenantClient, err := client.TenantManager.AuthForTenant(tenant.ID)
+		if err != nil {
+			log.Fatalf("error initializing client for tenant %s: %v\n", tenant.ID, err)
+		}
+		_, err = tenantClient.UpdateUser(ctx, uid, params)
+		if auth.IsUserNotFound(err) {
+			continue
+		}
+		if err != nil {
+			log.Fatalf("error disabling user in tenant %s: %v\n", tenant.ID, err)
+		}
+		log.Printf("Disabled user %s in tenant %s\n", uid, tenant.ID)
+	}
+	// [END disable_user_all_tenants]
+}
+
 // ==================================================================
 // https://firebase.google.com/docs/storage/admin/start
 // ==================================================================
@@ -524,6 +1053,246 @@ func cloudStorageCustomBucket(app *firebase.App) {
 	log.Printf("Created bucket handle: %v\n", bucket)
 }
 
+// ==================================================================
+// https://firebase.google.com/docs/auth/admin/email-action-links
+// ==================================================================
+
+func createUserRequireVerification(ctx context.Context, client *auth.Client) *auth.UserRecord {
+	// [START create_user_require_verification]
+	// Create the user with an unverified email address.
+	params := (&auth.UserToCreate{}).
+		Email("user@example.com").
+		EmailVerified(false).
+		Password("secretPassword").
+		DisplayName("John Doe")
+	u, err := client.CreateUser(ctx, params)
+	if err != nil {
+		log.Fatalf("error creating user: %v\n", err)
+	}
+	log.Printf("Successfully created user: %v\n", u)
+
+	// Generate a verification link for the new user. Deliver it with your
+	// own email service; EmailVerified becomes true once the user opens it.
+	link, err := client.EmailVerificationLink(ctx, u.Email)
+	if err != nil {
+		log.Fatalf("error generating email verification link: %v\n", err)
+	}
+	log.Printf("Email verification link: %s\n", link)
+	// [END create_user_require_verification]
+
+	return u
+}
+
+func generateVerifyAndChangeEmailLink(ctx context.Context, client *auth.Client, uid, newEmail string) string {
+	// [START verify_and_change_email_link]
+	u, err := client.GetUser(ctx, uid)
+	if err != nil {
+		log.Fatalf("error getting user %s: %v\n", uid, err)
+	}
+
+	// Unlike an email verification link, which confirms the current address,
+	// this link is sent to the new address, and the user's email only changes
+	// once the link is opened.
+	link, err := client.VerifyAndChangeEmailLink(ctx, u.Email, newEmail)
+	if err != nil {
+		log.Fatalf("error generating verify and change email link: %v\n", err)
+	}
+	log.Printf("Verify and change email link: %s\n", link)
+	// [END verify_and_change_email_link]
+
+	return link
+}
+
+// ==================================================================
+// https://firebase.google.com/docs/auth/admin/import-users
+// ==================================================================
+
+func importUserWithProvider(ctx context.Context, client *auth.Client) {
+	// [START import_user_with_provider]
+	// Users who sign in with a federated provider have no password to
+	// migrate. Import their provider identities instead, so they can keep
+	// signing in with that provider. Import password hashes only for users
+	// who sign in with an email and password.
+	users := []*auth.UserToImport{
+		(&auth.UserToImport{}).
+			UID("some-uid").
+			Email("user@example.com").
+			EmailVerified(true).
+			ProviderData([]*auth.UserProvider{
+				{
+					UID:         "google-uid",
+					ProviderID:  "google.com",
+					Email:       "user@example.com",
+					DisplayName: "John Doe",
+				},
+			}),
+	}
+	result, err := client.ImportUsers(ctx, users)
+	if err != nil {
+		log.Fatalln("Unrecoverable error prevented the operation from running", err)
+	}
+
+	log.Printf("Successfully imported %d users\n", result.SuccessCount)
+	log.Printf("Failed to import %d users\n", result.FailureCount)
+	for _, e := range result.Errors {
+		log.Printf("Failed to import user at index: %d due to error: %s\n", e.Index, e.Reason)
+	}
+	// [END import_user_with_provider]
+}
+
+// ==================================================================
+// https://firebase.google.com/docs/auth/admin/manage-mfa-users
+// ==================================================================
+
+func listEnrolledFactors(ctx context.Context, client *auth.Client, uid string) {
+	// [START list_mfa_factors]
+	u, err := client.GetUser(ctx, uid)
+	if err != nil {
+		log.Fatalf("error getting user %s: %v\n", uid, err)
+	}
+
+	// Users enroll second factors themselves through the client SDKs. The
+	// user record exposes the enrolled factors, and the list is empty when
+	// there are none.
+	if len(u.MultiFactor.EnrolledFactors) == 0 {
+		log.Printf("user %s has no enrolled second factors\n", uid)
+		return
+	}
+	for _, factor := range u.MultiFactor.EnrolledFactors {
+		log.Printf("factor %s (%s): %s, enrolled at %v\n",
+			factor.UID, factor.FactorID, factor.DisplayName,
+			millisToTime(factor.EnrollmentTimestamp))
+	}
+	// [END list_mfa_factors]
+}
+
+func enrollPhoneMFA(ctx context.Context, client *auth.Client, uid string) {
+	// [START enroll_mfa]
+	u, err := client.GetUser(ctx, uid)
+	if err != nil {
+		log.Fatalf("error getting user %s: %v\n", uid, err)
+	}
+
+	// The enrolled factors passed to UpdateUser replace the existing ones, so
+	// keep the factors the user already has, identified by their UID. Copy
+	// only the fields UpdateUser needs: EnrollmentTimestamp is read in
+	// milliseconds but written back in seconds, so leave it unset. UpdateUser
+	// also requires a display name, which client apps may have omitted.
+	var factors []*auth.MultiFactorInfo
+	for _, f := range u.MultiFactor.EnrolledFactors {
+		displayName := f.DisplayName
+		if displayName == "" {
+			displayName = "Second factor"
+		}
+		factors = append(factors, &auth.MultiFactorInfo{
+			UID:         f.UID,
+			DisplayName: displayName,
+			FactorID:    f.FactorID,
+			Phone:       f.Phone,
+		})
+	}
+
+	// Multi-factor authentication must be enabled for the project, and the
+	// phone number must be in E.164 format.
+	factors = append(factors, &auth.MultiFactorInfo{
+		DisplayName: "Work phone",
+		FactorID:    "phone",
+		Phone: &auth.PhoneMultiFactorInfo{
+			PhoneNumber: "+15555550100",
+		},
+	})
+	params := (&auth.UserToUpdate{}).
+		MFASettings(auth.MultiFactorSettings{EnrolledFactors: factors})
+	u, err = client.UpdateUser(ctx, uid, params)
+	if err != nil {
+		log.Fatalf("error enrolling second factor: %v\n", err)
+	}
+	log.Printf("User %s now has %d second factors\n", uid, len(u.MultiFactor.EnrolledFactors))
+	// [END enroll_mfa]
+}
+
+func unenrollAllMFA(ctx context.Context, client *auth.Client, uid string) {
+	// [START unenroll_mfa]
+	// Replacing the enrolled factors with an empty list removes all of them.
+	// This cannot be undone: the user signs in with the first factor only
+	// until they enroll a second factor again.
+	params := (&auth.UserToUpdate{}).
+		MFASettings(auth.MultiFactorSettings{EnrolledFactors: []*auth.MultiFactorInfo{}})
+	if _, err := client.UpdateUser(ctx, uid, params); err != nil {
+		log.Fatalf("error removing second factors: %v\n", err)
+	}
+	log.Printf("Removed all second factors of user %s\n", uid)
+	// [END unenroll_mfa]
+}
+
+// ==================================================================
+// https://firebase.google.com/docs/auth/admin/manage-cookies
+// ==================================================================
+
+func signupAndIssueCookie(ctx context.Context, client *auth.Client) string {
+	// [START signup_issue_cookie]
+	// 1. Create the user on the server.
+	params := (&auth.UserToCreate{}).
+		Email("user@example.com").
+		Password("secretPassword")
+	u, err := client.CreateUser(ctx, params)
+	if err != nil {
+		log.Fatalf("error creating user: %v\n", err)
+	}
+
+	// 2. Mint a custom token for the new user and return it to the client.
+	customToken, err := client.CustomToken(ctx, u.UID)
+	if err != nil {
+		log.Fatalf("error minting custom token: %v\n", err)
+	}
+
+	// 3. The client signs in with signInWithCustomToken(), which exchanges
+	// the custom token for an ID token, and posts that ID token back to the
+	// server.
+	//
+	// 4. The server verifies the ID token and exchanges it for a session
+	// cookie, as in the sessionLogin handler below.
+	log.Printf("Created user %s\n", u.UID)
+	// [END signup_issue_cookie]
+
+	return customToken
+}
+
+func sessionLogin(client *auth.Client) http.HandlerFunc {
+	// [START session_login]
+	// Register the handler once at startup, for example with
+	// http.Handle("/sessionLogin", sessionLogin(client)).
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Only accept ID tokens from a recent sign-in.
+		idToken := r.FormValue("idToken")
+		decoded, err := client.VerifyIDToken(r.Context(), idToken)
+		if err != nil {
+			http.Error(w, "Invalid ID token", http.StatusUnauthorized)
+			return
+		}
+		if time.Now().Unix()-decoded.AuthTime > 5*60 {
+			http.Error(w, "Recent sign-in required", http.StatusUnauthorized)
+			return
+		}
+
+		expiresIn := time.Hour * 24 * 5
+		cookie, err := client.SessionCookie(r.Context(), idToken, expiresIn)
+		if err != nil {
+			http.Error(w, "Failed to create a session cookie", http.StatusInternalServerError)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     "session",
+			Value:    cookie,
+			MaxAge:   int(expiresIn.Seconds()),
+			HttpOnly: true,
+			Secure:   true,
+		})
+		w.Write([]byte(`{"status": "success"}`))
+	}
+	// [END session_login]
+}
+
 func main() {
 	app := initializeAppWithServiceAccount()
 
@@ -15,12 +15,16 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
-	"firebase.google.com/go"
-	"firebase.google.com/go/messaging"
+	firebase "firebase.google.com/go/v4"
+	"firebase.google.com/go/v4/messaging"
 	"golang.org/x/net/context"
 )
 
@@ -257,3 +261,678 @@ func unsubscribeFromTopic(ctx context.Context, client *messaging.Client) {
 	fmt.Println(response.SuccessCount, "tokens were unsubscribed successfully")
 	// [END unsubscribe]
 }
+
+func sendAndCleanupTokens(ctx context.Context, client *messaging.Client) {
+	// [START fcm_error_handling]
+	// This registration token comes from the client FCM SDKs.
+	registrationToken := "YOUR_REGISTRATION_TOKEN"
+
+	message := &messaging.Message{
+		Data: map[string]string{
+			"score": "850",
+			"time":  "2:45",
+		},
+		Token: registrationToken,
+	}
+
+	const maxAttempts = 5
+	backoff := time.Second
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		response, err := client.Send(ctx, message)
+		if err == nil {
+			fmt.Println("Successfully sent message:", response)
+			return
+		}
+
+		switch {
+		case messaging.IsUnregistered(err):
+			// The app was uninstalled or the token expired. The token will never
+			// become valid again, so remove it from your database.
+			log.Printf("token %q is no longer registered; deleting it\n", registrationToken)
+			return
+		case messaging.IsInvalidArgument(err):
+			// The token is malformed, or the message payload is invalid. Retrying
+			// the same request will fail again. If the token was the problem,
+			// delete it from your database too.
+			log.Printf("invalid message or token %q: %v\n", registrationToken, err)
+			return
+		case messaging.IsQuotaExceeded(err):
+			// Sending limits were exceeded for this device or project. Keep the
+			// token and retry later with exponential backoff.
+			if attempt == maxAttempts {
+				break
+			}
+			log.Printf("quota exceeded; retrying in %v\n", backoff)
+			// Stop waiting if the context is cancelled or times out.
+			select {
+			case <-ctx.Done():
+				log.Printf("giving up: %v\n", ctx.Err())
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		default:
+			log.Printf("error sending message: %v\n", err)
+			return
+		}
+	}
+	log.Println("giving up after repeated quota errors")
+	// [END fcm_error_handling]
+}
+
+func sendRichNotification(ctx context.Context, client *messaging.Client) {
+	// [START fcm_rich_notification]
+	message := &messaging.Message{
+		Notification: &messaging.Notification{
+			Title: "$GOOG up 1.43% on the day",
+			Body:  "$GOOG gained 11.80 points to close at 835.67, up 1.43% on the day.",
+			// Android displays the image directly. On iOS the app needs a
+			// notification service extension to download and attach it, and
+			// the message must set mutable-content.
+			ImageURL: "https://my-server/chart.png",
+		},
+		Android: &messaging.AndroidConfig{
+			Notification: &messaging.AndroidNotification{
+				Sound: "default",
+				// The channel must already be created by the app on the device,
+				// otherwise the notification falls back to the default channel.
+				ChannelID: "stock_updates",
+			},
+		},
+		APNS: &messaging.APNSConfig{
+			Payload: &messaging.APNSPayload{
+				Aps: &messaging.Aps{
+					Sound:          "default",
+					MutableContent: true,
+				},
+			},
+		},
+		Topic: "industry-tech",
+	}
+
+	response, err := client.Send(ctx, message)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// Response is a message ID string.
+	fmt.Println("Successfully sent message:", response)
+	// [END fcm_rich_notification]
+}
+
+func sendCollapsibleMessage(ctx context.Context, client *messaging.Client) {
+	// [START fcm_collapse_key]
+	// Messages with the same collapse key replace each other on the device
+	// instead of stacking up. Android allows at most four distinct collapse
+	// keys per device at any given time.
+	message := &messaging.Message{
+		Notification: &messaging.Notification{
+			Title: "New scores available",
+			Body:  "Open the app to see the latest scores.",
+		},
+		Android: &messaging.AndroidConfig{
+			CollapseKey: "score_update",
+		},
+		APNS: &messaging.APNSConfig{
+			Headers: map[string]string{
+				"apns-collapse-id": "score_update",
+			},
+		},
+		Topic: "highScores",
+	}
+
+	response, err := client.Send(ctx, message)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// Response is a message ID string.
+	fmt.Println("Successfully sent message:", response)
+	// [END fcm_collapse_key]
+}
+
+func sendWithAPNSPriority(ctx context.Context, client *messaging.Client) {
+	// [START fcm_apns_priority]
+	// Expire the message if it cannot be delivered within the next hour.
+	expiration := time.Now().Add(time.Hour).Unix()
+	message := &messaging.Message{
+		APNS: &messaging.APNSConfig{
+			Headers: map[string]string{
+				// Priority 5 lets the device save power by delivering the
+				// message opportunistically. Background (content-available)
+				// messages must use priority 5, and APNs throttles them.
+				"apns-priority":   "5",
+				"apns-expiration": strconv.FormatInt(expiration, 10),
+			},
+			Payload: &messaging.APNSPayload{
+				Aps: &messaging.Aps{
+					ContentAvailable: true,
+				},
+			},
+		},
+		Topic: "industry-tech",
+	}
+
+	response, err := client.Send(ctx, message)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// Response is a message ID string.
+	fmt.Println("Successfully sent message:", response)
+	// [END fcm_apns_priority]
+}
+
+func sendAll(ctx context.Context, client *messaging.Client) {
+	// [START fcm_send_all]
+	// Each message can have its own target and payload, unlike a
+	// MulticastMessage, which sends the same payload to a list of tokens.
+	messages := []*messaging.Message{
+		{
+			Notification: &messaging.Notification{
+				Title: "Price drop",
+				Body:  "5% off all electronics",
+			},
+			Token: "YOUR_REGISTRATION_TOKEN",
+		},
+		{
+			Notification: &messaging.Notification{
+				Title: "Price drop",
+				Body:  "2% off all books",
+			},
+			Topic: "readers-club",
+		},
+		{
+			Notification: &messaging.Notification{
+				Title: "Price drop",
+				Body:  "10% off all music",
+			},
+			Condition: "'music' in topics && 'deals' in topics",
+		},
+	}
+
+	// Up to 500 messages can be sent in a single call. SendEach replaces the
+	// deprecated SendAll, whose batch endpoint is no longer available.
+	br, err := client.SendEach(ctx, messages)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	// Responses are in the same order as the input messages.
+	for i, resp := range br.Responses {
+		if resp.Success {
+			fmt.Printf("message %d sent: %s\n", i, resp.MessageID)
+		} else {
+			fmt.Printf("message %d failed: %v\n", i, resp.Error)
+		}
+	}
+	fmt.Printf("%d messages were sent successfully\n", br.SuccessCount)
+	// [END fcm_send_all]
+}
+
+func sendLocalizedAndroidNotification(ctx context.Context, client *messaging.Client) {
+	// [START fcm_android_localized]
+	// The localization keys name string resources in the Android app, so the
+	// device renders the notification in its own language. A single message
+	// serves every locale.
+	message := &messaging.Message{
+		Android: &messaging.AndroidConfig{
+			Notification: &messaging.AndroidNotification{
+				TitleLocKey: "stock_update_title",
+				BodyLocKey:  "stock_update_body",
+				BodyLocArgs: []string{"GOOG", "1.43"},
+				// The intent filter action of the activity to open on click.
+				ClickAction: "OPEN_STOCK_DETAILS",
+			},
+		},
+		Topic: "industry-tech",
+	}
+
+	response, err := client.Send(ctx, message)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// Response is a message ID string.
+	fmt.Println("Successfully sent message:", response)
+	// [END fcm_android_localized]
+}
+
+func sendDataOnly(ctx context.Context, client *messaging.Client) {
+	// [START fcm_data_only]
+	// A message without a notification is delivered to the app's message
+	// handler instead of being displayed by the system. When the app is in
+	// the background, Android only wakes it promptly for high priority
+	// messages, and iOS may throttle or drop content-available messages, so
+	// delivery of silent messages is not guaranteed.
+	message := &messaging.Message{
+		Data: map[string]string{
+			"score": "850",
+			"time":  "2:45",
+		},
+		Android: &messaging.AndroidConfig{
+			Priority: "high",
+		},
+		APNS: &messaging.APNSConfig{
+			Headers: map[string]string{
+				// Background messages must use priority 5.
+				"apns-priority": "5",
+			},
+			Payload: &messaging.APNSPayload{
+				Aps: &messaging.Aps{
+					ContentAvailable: true,
+				},
+			},
+		},
+		Topic: "highScores",
+	}
+
+	response, err := client.Send(ctx, message)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// Response is a message ID string.
+	fmt.Println("Successfully sent message:", response)
+	// [END fcm_data_only]
+}
+
+func sendAPNSWithCustomData(ctx context.Context, client *messaging.Client) {
+	// [START fcm_apns_custom_data]
+	message := &messaging.Message{
+		APNS: &messaging.APNSConfig{
+			Payload: &messaging.APNSPayload{
+				Aps: &messaging.Aps{
+					Alert: &messaging.ApsAlert{
+						Title: "$GOOG up 1.43% on the day",
+						Body:  "$GOOG gained 11.80 points to close at 835.67, up 1.43% on the day.",
+					},
+				},
+				// Custom keys are added at the top level of the APNS payload,
+				// next to "aps", where the iOS app can read them. They must not
+				// use the reserved "aps" key.
+				CustomData: map[string]interface{}{
+					"ticker": "GOOG",
+					"change": 1.43,
+				},
+			},
+		},
+		Topic: "industry-tech",
+	}
+
+	response, err := client.Send(ctx, message)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// Response is a message ID string.
+	fmt.Println("Successfully sent message:", response)
+	// [END fcm_apns_custom_data]
+}
+
+func validateToken(ctx context.Context, client *messaging.Client, token string) (bool, error) {
+	// [START fcm_validate_token]
+	// FCM has no endpoint for checking a registration token. Instead, send a
+	// message to it in dry run mode: it is validated, but never delivered.
+	message := &messaging.Message{
+		Data: map[string]string{
+			"validate": "true",
+		},
+		Token: token,
+	}
+	_, err := client.SendDryRun(ctx, message)
+	if err == nil {
+		return true, nil
+	}
+	if messaging.IsInvalidArgument(err) || messaging.IsUnregistered(err) {
+		// The token is malformed or no longer registered; don't store it.
+		log.Printf("dropping invalid token %q: %v\n", token, err)
+		return false, nil
+	}
+	// Other errors (for example quota or server errors) say nothing about
+	// the token itself, so report them to the caller instead.
+	return false, err
+	// [END fcm_validate_token]
+}
+
+func sendWebpushNotification(ctx context.Context, client *messaging.Client) {
+	// [START fcm_webpush]
+	// WebpushNotification supports options of the browser Notification API
+	// that the generic Notification type does not, such as badges and
+	// actions. The web app must register the firebase-messaging-sw.js
+	// service worker to display notifications while it is in the background.
+	message := &messaging.Message{
+		Webpush: &messaging.WebpushConfig{
+			Notification: &messaging.WebpushNotification{
+				Title: "$GOOG up 1.43% on the day",
+				Body:  "$GOOG gained 11.80 points to close at 835.67, up 1.43% on the day.",
+				Icon:  "https://my-server/icon.png",
+				Badge: "https://my-server/badge.png",
+				Actions: []*messaging.WebpushNotificationAction{
+					{
+						Action: "view",
+						Title:  "View chart",
+					},
+				},
+			},
+			FCMOptions: &messaging.WebpushFCMOptions{
+				// The page to open when the user clicks the notification. It
+				// must use HTTPS.
+				Link: "https://my-server/stocks/GOOG",
+			},
+		},
+		Topic: "industry-tech",
+	}
+
+	response, err := client.Send(ctx, message)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// Response is a message ID string.
+	fmt.Println("Successfully sent message:", response)
+	// [END fcm_webpush]
+}
+
+func sendWithAnalyticsLabel(ctx context.Context, client *messaging.Client) {
+	// [START fcm_analytics_label]
+	message := &messaging.Message{
+		Notification: &messaging.Notification{
+			Title: "Spring sale",
+			Body:  "Everything is 20% off this week.",
+		},
+		// The label is attached to the delivery data that FCM exports to
+		// BigQuery and shows in the console. It can be up to 50 characters
+		// long, using only letters, digits, and the characters - _ . ~ %.
+		FCMOptions: &messaging.FCMOptions{
+			AnalyticsLabel: "campaign_spring",
+		},
+		Topic: "deals",
+	}
+
+	response, err := client.Send(ctx, message)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// Response is a message ID string.
+	fmt.Println("Successfully sent message:", response)
+	// [END fcm_analytics_label]
+}
+
+func sendAndroidAdvancedNotification(ctx context.Context, client *messaging.Client) {
+	// [START fcm_android_advanced]
+	// On Android 8.0 (API level 26) and higher, sound, vibration, lights,
+	// and importance are properties of the notification channel, and the
+	// channel's settings take precedence. These fields only take full effect
+	// on older devices, or on a channel the app created with matching
+	// settings.
+	message := &messaging.Message{
+		Android: &messaging.AndroidConfig{
+			Notification: &messaging.AndroidNotification{
+				Title:               "$GOOG up 1.43% on the day",
+				Body:                "$GOOG gained 11.80 points to close at 835.67, up 1.43% on the day.",
+				ChannelID:           "stock_updates",
+				Priority:            messaging.PriorityHigh,
+				DefaultSound:        true,
+				VibrateTimingMillis: []int64{0, 500, 250, 500},
+				LightSettings: &messaging.LightSettings{
+					Color:                  "#f45342",
+					LightOnDurationMillis:  200,
+					LightOffDurationMillis: 800,
+				},
+			},
+		},
+		Topic: "industry-tech",
+	}
+
+	response, err := client.Send(ctx, message)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// Response is a message ID string.
+	fmt.Println("Successfully sent message:", response)
+	// [END fcm_android_advanced]
+}
+
+func sendWithTTL(ctx context.Context, client *messaging.Client) {
+	// [START fcm_ttl]
+	// Use the same time-to-live on every platform so the message expires
+	// at the same moment everywhere.
+	ttl := 30 * time.Minute
+
+	// Android takes a relative duration, while APNs takes an absolute UNIX
+	// timestamp. A TTL of 0 means "deliver now or drop": the message is
+	// discarded if the device cannot be reached immediately. For APNs that
+	// is an apns-expiration of "0" rather than the current time.
+	apnsExpiration := "0"
+	if ttl > 0 {
+		apnsExpiration = strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	}
+
+	message := &messaging.Message{
+		Notification: &messaging.Notification{
+			Title: "Flash sale",
+			Body:  "50% off for the next 30 minutes.",
+		},
+		Android: &messaging.AndroidConfig{
+			TTL: &ttl,
+		},
+		APNS: &messaging.APNSConfig{
+			Headers: map[string]string{
+				"apns-expiration": apnsExpiration,
+			},
+		},
+		Topic: "flash-sales",
+	}
+
+	response, err := client.Send(ctx, message)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// Response is a message ID string.
+	fmt.Println("Successfully sent message:", response)
+	// [END fcm_ttl]
+}
+
+func inspectTopicErrors(ctx context.Context, client *messaging.Client) {
+	// [START fcm_topic_errors]
+	// These registration tokens come from the client FCM SDKs.
+	registrationTokens := []string{
+		"YOUR_REGISTRATION_TOKEN_1",
+		// ...
+		"YOUR_REGISTRATION_TOKEN_n",
+	}
+
+	// A nil error only means the request succeeded; individual tokens can
+	// still fail to subscribe.
+	response, err := client.SubscribeToTopic(ctx, registrationTokens, "highScores")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	fmt.Println(response.SuccessCount, "tokens were subscribed successfully")
+
+	// Index is the position of the failed token in registrationTokens.
+	// Reason is the FCM error code for that token, such as UNREGISTERED,
+	// or a generic code like NOT_FOUND when FCM does not report one.
+	for _, e := range response.Errors {
+		token := registrationTokens[e.Index]
+		switch e.Reason {
+		case "UNREGISTERED", "INVALID_ARGUMENT", "NOT_FOUND", "SENDER_ID_MISMATCH":
+			// The token is no longer valid, is malformed, or belongs to
+			// another project. Remove it from your database.
+			log.Printf("dropping invalid token %s: %s\n", token, e.Reason)
+		case "QUOTA_EXCEEDED", "RESOURCE_EXHAUSTED", "UNAVAILABLE", "INTERNAL":
+			// Transient failure. Retry later with backoff.
+			log.Printf("retrying token %s later: %s\n", token, e.Reason)
+		default:
+			log.Printf("failed to subscribe token %s: %s\n", token, e.Reason)
+		}
+	}
+	// [END fcm_topic_errors]
+}
+
+func sendMutableContent(ctx context.Context, client *messaging.Client) {
+	// [START fcm_mutable_content]
+	// mutable-content hands the notification to the app's notification
+	// service extension before it is displayed, so the extension can modify
+	// it, for example to download an image and attach it. iOS only does this
+	// for notifications with a visible alert.
+	//
+	// The image URL is delivered in the fcm_options of the payload. Without
+	// mutable-content, or without an extension in the app (such as one using
+	// the FCM SDK's populateNotificationContent helper), the image is
+	// ignored and only the text is shown.
+	message := &messaging.Message{
+		APNS: &messaging.APNSConfig{
+			Payload: &messaging.APNSPayload{
+				Aps: &messaging.Aps{
+					Alert: &messaging.ApsAlert{
+						Title: "New photo",
+						Body:  "Alice shared a photo with you.",
+					},
+					MutableContent: true,
+				},
+			},
+			FCMOptions: &messaging.APNSFCMOptions{
+				ImageURL: "https://my-server/photo.png",
+			},
+		},
+		Token: "YOUR_REGISTRATION_TOKEN",
+	}
+
+	response, err := client.Send(ctx, message)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// Response is a message ID string.
+	fmt.Println("Successfully sent message:", response)
+	// [END fcm_mutable_content]
+}
+
+func sendAPNSLocalized(ctx context.Context, client *messaging.Client) {
+	// [START fcm_apns_localized]
+	// Instead of sending display text, send keys from the app's
+	// Localizable.strings file. iOS looks up each key in the user's language
+	// and substitutes the arguments into its format specifiers (%@), e.g.:
+	//
+	//   "ORDER_TITLE" = "Order shipped";
+	//   "ORDER_SUBTITLE" = "Order #%@";
+	//   "ORDER_BODY" = "%@ is on its way and arrives %@.";
+	//
+	// The loc keys replace Title, SubTitle and Body, so leave those unset.
+	message := &messaging.Message{
+		APNS: &messaging.APNSConfig{
+			Payload: &messaging.APNSPayload{
+				Aps: &messaging.Aps{
+					Alert: &messaging.ApsAlert{
+						TitleLocKey:     "ORDER_TITLE",
+						SubTitleLocKey:  "ORDER_SUBTITLE",
+						SubTitleLocArgs: []string{"1234"},
+						LocKey:          "ORDER_BODY",
+						LocArgs:         []string{"Your package", "tomorrow"},
+					},
+				},
+			},
+		},
+		Token: "YOUR_REGISTRATION_TOKEN",
+	}
+
+	response, err := client.Send(ctx, message)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// Response is a message ID string.
+	fmt.Println("Successfully sent message:", response)
+	// [END fcm_apns_localized]
+}
+
+func buildCondition(topics []string) (string, error) {
+	// [START fcm_build_condition]
+	// A condition may reference at most five topics.
+	const maxConditionTopics = 5
+	if len(topics) == 0 {
+		return "", errors.New("at least one topic is required")
+	}
+	if len(topics) > maxConditionTopics {
+		return "", fmt.Errorf("condition has %d topics, at most %d are allowed",
+			len(topics), maxConditionTopics)
+	}
+
+	// Topic names may only contain letters, digits and -_.~%, so they can be
+	// quoted without escaping.
+	validTopic := regexp.MustCompile(`^[a-zA-Z0-9-_.~%]+$`)
+	clauses := make([]string, len(topics))
+	for i, topic := range topics {
+		if !validTopic.MatchString(topic) {
+			return "", fmt.Errorf("invalid topic name: %q", topic)
+		}
+		clauses[i] = fmt.Sprintf("'%s' in topics", topic)
+	}
+
+	// Matches devices subscribed to any of the topics. Join with " && " to
+	// require all of them instead. To mix the two, wrap a group in
+	// parentheses, e.g. "'a' in topics && ('b' in topics || 'c' in topics)".
+	condition := strings.Join(clauses, " || ")
+	// [END fcm_build_condition]
+
+	return condition, nil
+}
+
+func sendAndroidBadgeCount(ctx context.Context, client *messaging.Client) {
+	// [START fcm_android_badge]
+	// The number of unread items, shown as the app icon badge.
+	unread := 3
+	message := &messaging.Message{
+		Notification: &messaging.Notification{
+			Title: "New messages",
+			Body:  "You have 3 unread messages.",
+		},
+		Android: &messaging.AndroidConfig{
+			Notification: &messaging.AndroidNotification{
+				// The count this notification contributes to the badge. Whether
+				// and how it is shown depends on the launcher: many launchers
+				// show a dot instead of a number, or ignore it entirely.
+				NotificationCount: &unread,
+			},
+		},
+		APNS: &messaging.APNSConfig{
+			Payload: &messaging.APNSPayload{
+				Aps: &messaging.Aps{
+					// On iOS the badge is set to this exact number. Use 0 to
+					// clear the badge.
+					Badge: &unread,
+				},
+			},
+		},
+		Token: "YOUR_REGISTRATION_TOKEN",
+	}
+
+	response, err := client.Send(ctx, message)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// Response is a message ID string.
+	fmt.Println("Successfully sent message:", response)
+	// [END fcm_android_badge]
+}
+
+func messageFromTemplate(base messaging.Message, token string) *messaging.Message {
+	// [START fcm_message_template]
+	// Copying the struct copies its pointers and maps, not what they point
+	// to. If per-recipient changes were made through those, every message
+	// built from the template would see them. Copy the parts that change.
+	msg := base
+	if base.Notification != nil {
+		n := *base.Notification
+		msg.Notification = &n
+	}
+	if base.Data != nil {
+		msg.Data = make(map[string]string, len(base.Data))
+		for k, v := range base.Data {
+			msg.Data[k] = v
+		}
+	}
+	// Android, APNS, Webpush and FCMOptions are still shared with base.
+	// Copy them the same way before modifying them.
+
+	// A message has exactly one target.
+	msg.Token = token
+	msg.Topic = ""
+	msg.Condition = ""
+	// [END fcm_message_template]
+
+	return &msg
+}
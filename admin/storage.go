@@ -0,0 +1,342 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"cloud.google.com/go/storage"
+	firebase "firebase.google.com/go/v4"
+	"github.com/google/uuid"
+	"google.golang.org/api/iterator"
+)
+
+// ==================================================================
+// https://firebase.google.com/docs/storage/admin/start
+// ==================================================================
+
+func uploadFile(ctx context.Context, app *firebase.App, localPath, objectName string) {
+	// [START storage_upload_file]
+	client, err := app.Storage(ctx)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	bucket, err := client.DefaultBucket()
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	f, err := os.Open(localPath)
+	if err != nil {
+		log.Fatalf("error opening %s: %v\n", localPath, err)
+	}
+	defer f.Close()
+
+	w := bucket.Object(objectName).NewWriter(ctx)
+	w.ContentType = "image/jpeg"
+	if _, err := io.Copy(w, f); err != nil {
+		log.Fatalf("error uploading %s: %v\n", objectName, err)
+	}
+	// The upload is only completed when the writer is closed. If Close is
+	// not called (or its error is ignored), the object is never created.
+	if err := w.Close(); err != nil {
+		log.Fatalf("error uploading %s: %v\n", objectName, err)
+	}
+	log.Printf("Uploaded %s to %s\n", localPath, objectName)
+	// [END storage_upload_file]
+}
+
+func downloadFile(ctx context.Context, bucket *storage.BucketHandle, objectName string) []byte {
+	// [START storage_download_file]
+	r, err := bucket.Object(objectName).NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		log.Printf("object %s does not exist\n", objectName)
+		return nil
+	}
+	if err != nil {
+		log.Fatalf("error opening %s: %v\n", objectName, err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		log.Fatalf("error reading %s: %v\n", objectName, err)
+	}
+	log.Printf("Downloaded %d bytes from %s\n", len(data), objectName)
+	// [END storage_download_file]
+
+	return data
+}
+
+func generateSignedURL(ctx context.Context, bucket *storage.BucketHandle, objectName string) string {
+	// [START storage_signed_url]
+	opts := &storage.SignedURLOptions{
+		Method:  "GET",
+		Expires: time.Now().Add(15 * time.Minute),
+		Scheme:  storage.SigningSchemeV4,
+	}
+	// When the app is initialized with a service account key file, the URL is
+	// signed locally with that key. With other credentials (for example the
+	// default credentials on Cloud Run or GCE) the client falls back to the IAM
+	// signBlob API, which requires the "Service Account Token Creator" role. You
+	// can also set GoogleAccessID and SignBytes on opts to control signing.
+	signedURL, err := bucket.SignedURL(objectName, opts)
+	if err != nil {
+		log.Fatalf("error signing URL for %s: %v\n", objectName, err)
+	}
+	log.Printf("Generated signed URL: %s\n", signedURL)
+	// [END storage_signed_url]
+
+	return signedURL
+}
+
+func listObjects(ctx context.Context, bucket *storage.BucketHandle, prefix string) {
+	// [START storage_list_objects]
+	// Cloud Storage has a flat namespace. Setting a delimiter groups every
+	// object whose name continues past the delimiter into a single synthetic
+	// prefix, which lets you browse the bucket as if it had folders.
+	query := &storage.Query{Prefix: prefix, Delimiter: "/"}
+	it := bucket.Objects(ctx, query)
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Fatalf("error listing objects: %v\n", err)
+		}
+		if attrs.Prefix != "" {
+			// A "directory" directly under prefix.
+			log.Printf("prefix: %s\n", attrs.Prefix)
+		} else {
+			log.Printf("object: %s\n", attrs.Name)
+		}
+	}
+	// [END storage_list_objects]
+}
+
+func updateObjectMetadata(ctx context.Context, bucket *storage.BucketHandle, objectName string) {
+	// [START storage_object_metadata]
+	obj := bucket.Object(objectName)
+
+	// Keys are merged into the existing custom metadata of the object.
+	update := storage.ObjectAttrsToUpdate{
+		Metadata: map[string]string{
+			"owner": "alice",
+		},
+	}
+	if _, err := obj.Update(ctx, update); err != nil {
+		log.Fatalf("error updating metadata for %s: %v\n", objectName, err)
+	}
+
+	attrs, err := obj.Attrs(ctx)
+	if err != nil {
+		log.Fatalf("error reading metadata for %s: %v\n", objectName, err)
+	}
+	log.Printf("Size: %d\n", attrs.Size)
+	log.Printf("ContentType: %s\n", attrs.ContentType)
+	log.Printf("Created: %v\n", attrs.Created)
+	// Files uploaded through the Firebase client SDKs also carry a
+	// "firebaseStorageDownloadTokens" key here, which backs their download URLs.
+	for key, value := range attrs.Metadata {
+		log.Printf("Metadata %s: %s\n", key, value)
+	}
+	// [END storage_object_metadata]
+}
+
+func copyObject(ctx context.Context, bucket *storage.BucketHandle, src, dst string) {
+	// [START storage_copy_object]
+	// The copy keeps the metadata of the source object, unless you set
+	// fields of copier.ObjectAttrs before calling Run. The source object is
+	// left in place.
+	copier := bucket.Object(dst).CopierFrom(bucket.Object(src))
+	attrs, err := copier.Run(ctx)
+	if err != nil {
+		log.Fatalf("error copying %s to %s: %v\n", src, dst, err)
+	}
+	log.Printf("Copied %s to %s\n", src, attrs.Name)
+
+	// To copy into another bucket, use a destination handle from that bucket:
+	//
+	//	otherBucket, err := client.Bucket("my-other-bucket")
+	//	copier := otherBucket.Object(dst).CopierFrom(bucket.Object(src))
+	// [END storage_copy_object]
+}
+
+func moveObject(ctx context.Context, srcBucket, dstBucket *storage.BucketHandle, src, dst string) error {
+	// [START storage_move_object]
+	srcObj := srcBucket.Object(src)
+
+	// Within a bucket, Move renames the object atomically.
+	if srcBucket.BucketName() == dstBucket.BucketName() {
+		if _, err := srcObj.Move(ctx, storage.MoveObjectDestination{Object: dst}); err != nil {
+			return fmt.Errorf("error moving %s to %s: %v", src, dst, err)
+		}
+		log.Printf("Moved %s to %s\n", src, dst)
+		return nil
+	}
+
+	// Between buckets there is no atomic move, so copy the object and then
+	// delete the source.
+	if _, err := dstBucket.Object(dst).CopierFrom(srcObj).Run(ctx); err != nil {
+		return fmt.Errorf("error copying %s to %s: %v", src, dst, err)
+	}
+	if err := srcObj.Delete(ctx); err != nil {
+		// The copy succeeded, so both objects now exist. Report it so the
+		// caller can retry the delete instead of leaving a stray copy.
+		return fmt.Errorf("copied %s to %s, but failed to delete the source: %v", src, dst, err)
+	}
+	log.Printf("Moved %s to %s\n", src, dst)
+	// [END storage_move_object]
+
+	return nil
+}
+
+func uploadLargeFile(ctx context.Context, bucket *storage.BucketHandle, localPath, objectName string) {
+	// [START storage_large_upload]
+	f, err := os.Open(localPath)
+	if err != nil {
+		log.Fatalf("error opening %s: %v\n", localPath, err)
+	}
+	defer f.Close()
+
+	w := bucket.Object(objectName).NewWriter(ctx)
+	// Data is uploaded in chunks of ChunkSize bytes with a resumable upload,
+	// and a failed chunk is retried without restarting the whole upload. The
+	// default is 16 MiB. Each writer buffers a full chunk in memory, so larger
+	// chunks use more memory but need fewer requests, which speeds up
+	// multi-GB uploads. Setting ChunkSize to 0 uploads in a single request
+	// with no retries.
+	w.ChunkSize = 32 * 1024 * 1024
+	if _, err := io.Copy(w, f); err != nil {
+		log.Fatalf("error uploading %s: %v\n", objectName, err)
+	}
+	if err := w.Close(); err != nil {
+		log.Fatalf("error uploading %s: %v\n", objectName, err)
+	}
+	log.Printf("Uploaded %s to %s\n", localPath, objectName)
+	// [END storage_large_upload]
+}
+
+func uploadWithDownloadToken(ctx context.Context, bucket *storage.BucketHandle, objectName string) string {
+	// [START storage_download_token]
+	// Download URLs of the Firebase client SDKs are backed by the token
+	// stored in the firebaseStorageDownloadTokens metadata key. Setting it
+	// on upload produces the same kind of URL on the server.
+	token := uuid.NewString()
+
+	w := bucket.Object(objectName).NewWriter(ctx)
+	w.ContentType = "text/plain"
+	w.Metadata = map[string]string{
+		"firebaseStorageDownloadTokens": token,
+	}
+	if _, err := w.Write([]byte("Hello, world!")); err != nil {
+		log.Fatalf("error uploading %s: %v\n", objectName, err)
+	}
+	if err := w.Close(); err != nil {
+		log.Fatalf("error uploading %s: %v\n", objectName, err)
+	}
+
+	// Anyone with this URL can download the file, until the token is removed
+	// from the metadata.
+	downloadURL := fmt.Sprintf(
+		"https://firebasestorage.googleapis.com/v0/b/%s/o/%s?alt=media&token=%s",
+		bucket.BucketName(), url.PathEscape(objectName), token)
+	log.Printf("Download URL: %s\n", downloadURL)
+	// [END storage_download_token]
+
+	return downloadURL
+}
+
+func deletePrefix(ctx context.Context, bucket *storage.BucketHandle, prefix string) []error {
+	// [START storage_delete_prefix]
+	// Cloud Storage has no atomic "delete folder" operation. Each object
+	// under the prefix is deleted individually, so this is best-effort: if it
+	// fails part way, some objects are gone and others remain. Failures are
+	// collected so the remaining objects can be retried.
+	var errs []error
+	it := bucket.Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error listing objects: %v", err))
+			break
+		}
+		if err := bucket.Object(attrs.Name).Delete(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("error deleting %s: %v", attrs.Name, err))
+			continue
+		}
+		log.Printf("Deleted %s\n", attrs.Name)
+	}
+	// [END storage_delete_prefix]
+
+	return errs
+}
+
+func createResumableSession(ctx context.Context, bucket *storage.BucketHandle, objectName string) string {
+	// [START storage_resumable_session]
+	// Sign a URL that may only be used to start a resumable upload of this
+	// object. Signed headers must be sent with exactly these values.
+	opts := &storage.SignedURLOptions{
+		Method:      "POST",
+		Expires:     time.Now().Add(10 * time.Minute),
+		Scheme:      storage.SigningSchemeV4,
+		ContentType: "application/octet-stream",
+		Headers:     []string{"x-goog-resumable:start"},
+	}
+	signedURL, err := bucket.SignedURL(objectName, opts)
+	if err != nil {
+		log.Fatalf("error signing URL for %s: %v\n", objectName, err)
+	}
+
+	// Start the session. The session URI in the Location header is the
+	// upload URL: the client PUTs the file's bytes to it directly, and can
+	// query it and resume after an interruption, without the data passing
+	// through this server. The URI expires after one week and acts as an
+	// authorization token, so only hand it to the client doing the upload.
+	// For uploads from browsers, also send the Origin header the browser will
+	// use, so that Cloud Storage returns CORS headers for that origin.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, signedURL, nil)
+	if err != nil {
+		log.Fatalf("error creating request: %v\n", err)
+	}
+	req.Header.Set("Content-Type", opts.ContentType)
+	req.Header.Set("x-goog-resumable", "start")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Fatalf("error starting upload session: %v\n", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		log.Fatalf("error starting upload session: %s\n", resp.Status)
+	}
+	sessionURL := resp.Header.Get("Location")
+	log.Printf("Resumable upload session: %s\n", sessionURL)
+	// [END storage_resumable_session]
+
+	return sessionURL
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// [START appcheck_import]
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	firebase "firebase.google.com/go/v4"
+	"firebase.google.com/go/v4/appcheck"
+	"golang.org/x/oauth2/google"
+)
+
+// [END appcheck_import]
+
+// ==================================================================
+// https://firebase.google.com/docs/app-check/custom-resource-backend
+// ==================================================================
+
+func verifyAppCheckToken(ctx context.Context, app *firebase.App, token string) bool {
+	// [START appcheck_verify_token]
+	client, err := app.AppCheck(ctx)
+	if err != nil {
+		log.Fatalf("error getting App Check client: %v\n", err)
+	}
+
+	// The token is sent by the client app in the X-Firebase-AppCheck header.
+	decoded, err := client.VerifyToken(token)
+	if err != nil {
+		// Reject the request, for example with 401 Unauthorized.
+		log.Printf("invalid App Check token: %v\n", err)
+		return false
+	}
+	log.Printf("Verified App Check token for app: %s\n", decoded.AppID)
+	// [END appcheck_verify_token]
+
+	return true
+}
+
+func verifyAppCheckTokenConsume(ctx context.Context, client *appcheck.Client, projectID, token string) bool {
+	// [START appcheck_verify_consume]
+	// Replay protection is meant for limited-use tokens, which client apps
+	// request with getLimitedUseToken() and send for a single sensitive call.
+	// Consuming a token costs an extra network round trip, so only enable it
+	// for endpoints where a replayed request would do harm.
+	if _, err := client.VerifyToken(token); err != nil {
+		log.Printf("invalid App Check token: %v\n", err)
+		return false
+	}
+
+	// The Go SDK does not consume tokens itself, so call the App Check REST
+	// API, which marks the token as consumed and reports whether it already was.
+	hc, err := google.DefaultClient(ctx, "https://www.googleapis.com/auth/firebase")
+	if err != nil {
+		log.Fatalf("error creating HTTP client: %v\n", err)
+	}
+	body, err := json.Marshal(map[string]string{"appCheckToken": token})
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// The Admin SDKs call this method through the v1beta API.
+	url := fmt.Sprintf(
+		"https://firebaseappcheck.googleapis.com/v1beta/projects/%s:verifyAppCheckToken", projectID)
+	resp, err := hc.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Fatalf("error consuming App Check token: %v\n", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("error consuming App Check token: %s\n", resp.Status)
+	}
+
+	var result struct {
+		AlreadyConsumed bool `json:"alreadyConsumed"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		log.Fatalf("error decoding response: %v\n", err)
+	}
+	if result.AlreadyConsumed {
+		// The token was used before; reject the request as a possible replay.
+		log.Println("App Check token was already consumed")
+		return false
+	}
+	// [END appcheck_verify_consume]
+
+	return true
+}
+
+func main() {
+	app, err := firebase.NewApp(context.Background(), nil)
+	if err != nil {
+		log.Fatalf("error initializing app: %v\n", err)
+	}
+
+	_ = verifyAppCheckToken(context.Background(), app, "some-token")
+}
@@ -0,0 +1,330 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// [START rc_import]
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	firebase "firebase.google.com/go/v4"
+	"golang.org/x/oauth2/google"
+)
+
+// [END rc_import]
+
+// ==================================================================
+// https://firebase.google.com/docs/remote-config/server
+// ==================================================================
+
+func getServerTemplate(ctx context.Context, app *firebase.App) {
+	// [START rc_get_template]
+	client, err := app.RemoteConfig(ctx)
+	if err != nil {
+		log.Fatalf("error getting Remote Config client: %v\n", err)
+	}
+
+	// In-app defaults are used for parameters missing from the template.
+	defaultConfig := map[string]any{
+		"enable_new_checkout": false,
+		"welcome_message":     "Welcome!",
+	}
+	template, err := client.GetServerTemplate(ctx, defaultConfig)
+	if err != nil {
+		log.Fatalf("error fetching server template: %v\n", err)
+	}
+
+	// Evaluate the template's conditions against the current request.
+	config, err := template.Evaluate(map[string]any{
+		"randomizationID": "some-user-id",
+	})
+	if err != nil {
+		log.Fatalf("error evaluating server template: %v\n", err)
+	}
+
+	enableNewCheckout := config.GetBoolean("enable_new_checkout")
+	welcomeMessage := config.GetString("welcome_message")
+	log.Printf("enable_new_checkout: %v, welcome_message: %s\n",
+		enableNewCheckout, welcomeMessage)
+	// [END rc_get_template]
+}
+
+// ==================================================================
+// https://firebase.google.com/docs/remote-config/automate-rc
+// ==================================================================
+
+// [START rc_template_types]
+// The Go Admin SDK only evaluates server templates. Client templates are
+// managed through the Remote Config REST API using these types.
+const templateURL = "https://firebaseremoteconfig.googleapis.com/v1/projects/my-project-id/remoteConfig"
+
+type remoteConfigTemplate struct {
+	Conditions []condition          `json:"conditions,omitempty"`
+	Parameters map[string]parameter `json:"parameters,omitempty"`
+	Version    *templateVersion     `json:"version,omitempty"`
+}
+
+type condition struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+}
+
+type parameter struct {
+	DefaultValue      *parameterValue           `json:"defaultValue,omitempty"`
+	ConditionalValues map[string]parameterValue `json:"conditionalValues,omitempty"`
+	Description       string                    `json:"description,omitempty"`
+}
+
+type parameterValue struct {
+	Value string `json:"value"`
+}
+
+type templateVersion struct {
+	VersionNumber string `json:"versionNumber"`
+	UpdateTime    string `json:"updateTime"`
+	UpdateUser    struct {
+		Email string `json:"email"`
+	} `json:"updateUser"`
+	Description string `json:"description,omitempty"`
+}
+
+// [END rc_template_types]
+
+func publishTemplate(ctx context.Context, hc *http.Client) {
+	// [START rc_publish_template]
+	// Fetch the current template along with its ETag. Publishing replaces
+	// the whole template, so change the fetched template rather than
+	// publishing a new one, which would delete every other parameter and
+	// condition. The template is decoded into generic maps so that fields
+	// this code does not know about are sent back unchanged.
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, templateURL, nil)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		log.Fatalf("error fetching template: %v\n", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		log.Fatalf("error fetching template: %s\n", resp.Status)
+	}
+	etag := resp.Header.Get("ETag")
+	var template map[string]interface{}
+	err = json.NewDecoder(resp.Body).Decode(&template)
+	resp.Body.Close()
+	if err != nil {
+		log.Fatalf("error decoding template: %v\n", err)
+	}
+
+	// Add the android_users condition, or replace it if it already exists.
+	androidUsers := condition{
+		Name:       "android_users",
+		Expression: "device.os == 'android'",
+	}
+	conditions, _ := template["conditions"].([]interface{})
+	found := false
+	for i, c := range conditions {
+		if c.(map[string]interface{})["name"] == androidUsers.Name {
+			conditions[i] = androidUsers
+			found = true
+			break
+		}
+	}
+	if !found {
+		conditions = append(conditions, androidUsers)
+	}
+	template["conditions"] = conditions
+
+	// Add or update the welcome_message parameter, keeping all others.
+	parameters, _ := template["parameters"].(map[string]interface{})
+	if parameters == nil {
+		parameters = map[string]interface{}{}
+	}
+	parameters["welcome_message"] = parameter{
+		DefaultValue: &parameterValue{Value: "Welcome!"},
+		ConditionalValues: map[string]parameterValue{
+			"android_users": {Value: "Welcome, Android user!"},
+		},
+	}
+	template["parameters"] = parameters
+
+	// Check the modified template before publishing it.
+	if err := validateTemplate(ctx, hc, template); err != nil {
+		log.Fatalln(err)
+	}
+
+	body, err := json.Marshal(template)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodPut, templateURL, bytes.NewReader(body))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	req.Header.Set("Content-Type", "application/json; UTF-8")
+	// The publish only succeeds if the template has not changed since it was
+	// fetched. Send "If-Match: *" instead to force-publish over any changes.
+	req.Header.Set("If-Match", etag)
+	resp, err = hc.Do(req)
+	if err != nil {
+		log.Fatalf("error publishing template: %v\n", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		log.Fatalln("template was modified concurrently; fetch it and try again")
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("error publishing template: %s\n", resp.Status)
+	}
+
+	var published remoteConfigTemplate
+	if err := json.NewDecoder(resp.Body).Decode(&published); err != nil {
+		log.Fatalf("error decoding template: %v\n", err)
+	}
+	log.Printf("Published template version: %s\n", published.Version.VersionNumber)
+	// [END rc_publish_template]
+}
+
+func validateTemplate(ctx context.Context, hc *http.Client, template map[string]interface{}) error {
+	// [START rc_validate_template]
+	body, err := json.Marshal(template)
+	if err != nil {
+		return err
+	}
+
+	// validateOnly checks the template without publishing it, which makes it
+	// suitable for running in CI before a template change is merged.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut,
+		templateURL+"?validateOnly=true", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; UTF-8")
+	req.Header.Set("If-Match", "*")
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// The response body describes why the template was rejected.
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("template is invalid: %s: %s", resp.Status, msg)
+	}
+	log.Println("Template is valid and can be published")
+	// [END rc_validate_template]
+
+	return nil
+}
+
+func validateMalformedTemplate(ctx context.Context, hc *http.Client) {
+	// [START rc_validate_malformed]
+	// This condition expression uses = instead of ==, so validation fails and
+	// the error describes the problem.
+	template := map[string]interface{}{
+		"conditions": []condition{
+			{
+				Name:       "android_users",
+				Expression: "device.os = 'android'",
+			},
+		},
+	}
+	if err := validateTemplate(ctx, hc, template); err != nil {
+		log.Printf("validation failed: %v\n", err)
+	}
+	// [END rc_validate_malformed]
+}
+
+func listVersionsAndRollback(ctx context.Context, hc *http.Client) {
+	// [START rc_version_history]
+	// Remote Config keeps up to 300 template versions, and versions older
+	// than 90 days are deleted, so old versions may no longer be available.
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		templateURL+":listVersions?pageSize=10", nil)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		log.Fatalf("error listing versions: %v\n", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("error listing versions: %s\n", resp.Status)
+	}
+
+	var result struct {
+		Versions []templateVersion `json:"versions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		log.Fatalf("error decoding versions: %v\n", err)
+	}
+	// Versions are listed newest first.
+	for _, v := range result.Versions {
+		log.Printf("version %s updated at %s by %s\n",
+			v.VersionNumber, v.UpdateTime, v.UpdateUser.Email)
+	}
+	if len(result.Versions) < 2 {
+		log.Println("no earlier version to roll back to")
+		return
+	}
+
+	// Rolling back publishes a copy of the earlier version as a new version.
+	previous := result.Versions[1].VersionNumber
+	body, err := json.Marshal(map[string]string{"versionNumber": previous})
+	if err != nil {
+		log.Fatalln(err)
+	}
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost,
+		templateURL+":rollback", bytes.NewReader(body))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	req.Header.Set("Content-Type", "application/json; UTF-8")
+	rollbackResp, err := hc.Do(req)
+	if err != nil {
+		log.Fatalf("error rolling back: %v\n", err)
+	}
+	defer rollbackResp.Body.Close()
+	if rollbackResp.StatusCode != http.StatusOK {
+		log.Fatalf("error rolling back: %s\n", rollbackResp.Status)
+	}
+	log.Printf("Rolled back to version %s\n", previous)
+	// [END rc_version_history]
+}
+
+func main() {
+	app, err := firebase.NewApp(context.Background(), nil)
+	if err != nil {
+		log.Fatalf("error initializing app: %v\n", err)
+	}
+
+	getServerTemplate(context.Background(), app)
+
+	hc, err := google.DefaultClient(context.Background(),
+		"https://www.googleapis.com/auth/firebase.remoteconfig")
+	if err != nil {
+		log.Fatalf("error creating HTTP client: %v\n", err)
+	}
+	publishTemplate(context.Background(), hc)
+}